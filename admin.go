@@ -0,0 +1,143 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+// maxDelay limits poll delay set by /setdelay in milliseconds
+const maxDelay = 10000
+
+// pollDelay contains delay between polling requests in milliseconds, it's
+// read by poller after each request and can be changed by /setdelay
+var pollDelay atomic.Int64
+
+func isAdmin(userID int) bool {
+	return slices.Contains(botConfig.Admins, userID)
+}
+
+// adminAction handles /admin <command> ... commands of users listed in admins
+// config field, commands of other users are ignored
+func adminAction(req tbf.Request) {
+	if !isAdmin(req.Message.From.ID) {
+		slog.Warn("Admin command from non-admin user", "user_id", req.Message.From.ID)
+		return
+	}
+
+	lang := userLanguage(req.Message.From)
+	args := commandArgs(req.Message.Text)
+	if len(args) == 0 {
+		req.QuickMessage(tr(lang, "admin.usage"))
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "broadcast":
+		broadcastAction(req, strings.Join(args[1:], " "))
+	default:
+		req.QuickMessage(tr(lang, "admin.usage"))
+	}
+}
+
+// broadcastAction sends the text to all chats having active games
+func broadcastAction(req tbf.Request, text string) {
+	lang := userLanguage(req.Message.From)
+	if len(text) == 0 {
+		req.QuickMessage(tr(lang, "admin.usage"))
+		return
+	}
+
+	chats := games.Chats(shardID(req.Bot))
+	sent := 0
+	for _, chatID := range chats {
+		_, err := req.Bot.SendMessage(tgbot.SendMessageConfig{
+			ChatID: tgbot.ChatID(chatID),
+			Text:   text,
+		})
+
+		if err != nil {
+			apiErrors.Inc()
+			slog.Error("Unable to send broadcast", "chat_id", chatID, "error", err)
+			continue
+		}
+
+		sent++
+	}
+
+	slog.Info("Broadcast sent", "user_id", req.Message.From.ID, "sent", sent, "chats", len(chats))
+	req.QuickMessage(tr(lang, "admin.broadcast_sent", sent, len(chats)))
+}
+
+// leaderboardAction shows the scoreboard, /leaderboard reset archives
+// and clears it and is available only to admins
+func leaderboardAction(req tbf.Request) {
+	args := commandArgs(req.Message.Text)
+	if len(args) == 0 {
+		scoreboardAction(req)
+		return
+	}
+
+	lang := userLanguage(req.Message.From)
+	if strings.ToLower(args[0]) != "reset" {
+		req.QuickMessage(tr(lang, "leaderboard.usage"))
+		return
+	}
+
+	if !isAdmin(req.Message.From.ID) {
+		slog.Warn("Leaderboard reset from non-admin user", "user_id", req.Message.From.ID)
+		req.QuickMessage(tr(lang, "leaderboard.not_admin"))
+		return
+	}
+
+	scores := scoreboard.Snapshot()
+	path, err := archiveScores(scores, time.Now())
+	if err != nil {
+		slog.Error("Unable to archive scores", "error", err)
+		req.QuickMessage(tr(lang, "leaderboard.archive_failed"))
+		return
+	}
+
+	scoreboard.Reset()
+	if len(botConfig.StatePath) > 0 {
+		if err := saveState(botConfig.StatePath); err != nil {
+			slog.Error("Unable to save state", "error", err)
+		}
+	}
+
+	slog.Info("Leaderboard reset", "user_id", req.Message.From.ID, "scores", len(scores), "archive", path)
+	req.QuickMessage(tr(lang, "leaderboard.reset", len(scores)))
+}
+
+// setDelayAction changes poll delay of all bots without restart, new value
+// is used after the next polling request
+func setDelayAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	if !isAdmin(req.Message.From.ID) {
+		slog.Warn("Delay change from non-admin user", "user_id", req.Message.From.ID)
+		req.QuickMessage(tr(lang, "delay.not_admin"))
+		return
+	}
+
+	args := commandArgs(req.Message.Text)
+	if len(args) != 1 {
+		req.QuickMessage(tr(lang, "delay.usage", pollDelay.Load(), maxDelay))
+		return
+	}
+
+	delay, err := strconv.Atoi(args[0])
+	if err != nil || delay < 0 || delay > maxDelay {
+		req.QuickMessage(tr(lang, "delay.usage", pollDelay.Load(), maxDelay))
+		return
+	}
+
+	previous := pollDelay.Swap(int64(delay))
+	slog.Info("Poll delay changed", "user_id", req.Message.From.ID, "previous", previous, "delay", delay)
+	req.QuickMessage(tr(lang, "delay.set", delay))
+}
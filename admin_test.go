@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestIsAdmin(t *testing.T) {
+	tests := []struct {
+		name   string
+		admins []int
+		userID int
+		want   bool
+	}{
+		{"no admins", nil, 1, false},
+		{"admin", []int{1, 2}, 2, true},
+		{"other user", []int{1, 2}, 3, false},
+	}
+
+	for _, test := range tests {
+		withConfig(t, BotConfig{Admins: test.admins})
+		if got := isAdmin(test.userID); got != test.want {
+			t.Errorf("%s: isAdmin(%d) = %v, want %v", test.name, test.userID, got, test.want)
+		}
+	}
+}
+
+func TestAdminActionIgnoresOtherUsers(t *testing.T) {
+	withConfig(t, BotConfig{Admins: []int{1}})
+
+	// request has no bot, so any reply would panic
+	adminAction(testRequest(2, "/admin broadcast hello"))
+}
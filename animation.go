@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	animationSteps   = 3
+	animationDelay   = 300 * time.Millisecond
+	minAnimatedCells = 8
+)
+
+// floodFrames returns board edits showing cells opened by the move in a few
+// steps spreading from the tapped cell before the final board is rendered, it's
+// enabled by animate_flood option and stops early when the player runs out of
+// rate limit. Game lock should be held
+func floodFrames(game *Game, player *tgbot.User, row, col int, opened []int) []tgbot.EditMessageTextConfig {
+	if !botConfig.AnimateFlood || len(opened) < minAnimatedCells {
+		return nil
+	}
+
+	minefield := game.Minefield
+	distance := func(index int) int {
+		return max(abs(index/minefield.Width-row), abs(index%minefield.Width-col))
+	}
+
+	maxDistance := 0
+	for _, index := range opened {
+		maxDistance = max(maxDistance, distance(index))
+		minefield.Field[index/minefield.Width][index%minefield.Width].State = StateClosed
+	}
+
+	state := minefield.State
+	minefield.State = GameRunning
+	var frames []tgbot.EditMessageTextConfig
+	for step := 1; step < animationSteps; step++ {
+		if limiter != nil && !limiter.Allow(player.ID) {
+			break
+		}
+
+		for _, index := range opened {
+			if distance(index) <= maxDistance*step/animationSteps {
+				minefield.Field[index/minefield.Width][index%minefield.Width].State = StateOpened
+			}
+		}
+
+		config := game.EditConfig()
+		config.Text = renderText(game, tr(game.Language, "game.title"))
+		config.ReplyMarkup = renderMinefield(game)
+		frames = append(frames, config)
+	}
+
+	for _, index := range opened {
+		minefield.Field[index/minefield.Width][index%minefield.Width].State = StateOpened
+	}
+
+	minefield.State = state
+	return frames
+}
+
+// showFrames sends animation frames releasing the game lock held by the caller,
+// so other moves and commands aren't blocked while it waits between frames.
+// The lock is held again when it returns, it reports if the game is still
+// active as it could be finished, cancelled or replaced meanwhile
+func showFrames(bot *tgbot.TelegramBot, game *Game, frames []tgbot.EditMessageTextConfig) bool {
+	if len(frames) == 0 {
+		return true
+	}
+
+	// the move keeps the game from being ended as idle while frames are shown
+	game.LastMoveAt = time.Now()
+	key := game.Key()
+	game.mu.Unlock()
+	for _, config := range frames {
+		if err := editMessage(bot, config); err != nil {
+			slog.Error("Unable to animate board", "game", key, "error", err)
+			break
+		}
+
+		time.Sleep(animationDelay)
+	}
+
+	game.mu.Lock()
+
+	// frames replaced the board, so the final one is sent even if it's the same
+	game.signature = ""
+	current, ok := games.Get(key)
+	return ok && current == game
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/floodcode/tgbot"
+)
+
+func TestFloodAnimationReleasesLock(t *testing.T) {
+	withConfig(t, BotConfig{AnimateFlood: true})
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+
+	game := &Game{Minefield: testMinefield(".....", ".....", ".....", ".....", "....*")}
+	games.Set(game)
+
+	var unlocked, locked int
+	previous := sendEdit
+	sendEdit = func(*tgbot.TelegramBot, tgbot.EditMessageTextConfig) error {
+		if game.mu.TryLock() {
+			game.mu.Unlock()
+			unlocked++
+		} else {
+			locked++
+		}
+
+		return nil
+	}
+
+	defer func() { sendEdit = previous }()
+
+	playCallback(nil, game, &tgbot.User{ID: 1}, CellCallbackData{Row: 0, Col: 0})
+	if unlocked != animationSteps-1 {
+		t.Errorf("%d frames sent without the game lock, want %d", unlocked, animationSteps-1)
+	}
+
+	if locked != 1 {
+		t.Errorf("%d boards sent holding the game lock, want the final one", locked)
+	}
+
+	if game.Minefield.State != GameWin {
+		t.Errorf("state after the move = %d, want win", game.Minefield.State)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gameSeparator separates optional game ID prefix from the rest of callback data
+const gameSeparator = "|"
+
+// encodeCallbackData packs callback data into short string to fit into
+// 64 bytes limit, cells are encoded like 3,5 and other buttons by action
+// optionally followed by its value like theme:dark, game ID is prepended
+// like 12:34|3,5 when it's set
+func encodeCallbackData(data CellCallbackData) string {
+	payload := strconv.Itoa(data.Row) + "," + strconv.Itoa(data.Col)
+	if len(data.Action) > 0 {
+		payload = data.Action
+	}
+
+	if len(data.Value) > 0 {
+		payload += ":" + data.Value
+	}
+
+	if len(data.Game) > 0 {
+		return data.Game + gameSeparator + payload
+	}
+
+	return payload
+}
+
+// decodeCallbackData unpacks callback data, JSON encoded data of boards
+// sent by older versions is accepted as well
+func decodeCallbackData(data string) (CellCallbackData, error) {
+	var cellData CellCallbackData
+	if strings.HasPrefix(data, "{") {
+		err := json.Unmarshal([]byte(data), &cellData)
+		return cellData, err
+	}
+
+	if game, payload, ok := strings.Cut(data, gameSeparator); ok {
+		cellData.Game = game
+		data = payload
+	}
+
+	rowText, colText, ok := strings.Cut(data, ",")
+	if !ok {
+		cellData.Action, cellData.Value, _ = strings.Cut(data, ":")
+		switch cellData.Action {
+		case actionToggleMode, actionRestart, actionNoop, actionTheme, actionPlay, actionWatch, actionGiveUp, actionScroll:
+			return cellData, nil
+		}
+
+		return CellCallbackData{}, fmt.Errorf("unknown action %q", data)
+	}
+
+	row, rowErr := strconv.Atoi(rowText)
+	col, colErr := strconv.Atoi(colText)
+	if err := errors.Join(rowErr, colErr); err != nil {
+		return CellCallbackData{}, err
+	}
+
+	cellData.Row = row
+	cellData.Col = col
+	return cellData, nil
+}
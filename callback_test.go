@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCallbackDataRoundTrip(t *testing.T) {
+	// longest game ID is a key of a supergroup message
+	game := messageKey(-1001234567890, 2147483647)
+	last := strconv.Itoa(maxSize-1) + "," + strconv.Itoa(maxSize-1)
+	tests := []struct {
+		data CellCallbackData
+		want string
+	}{
+		{CellCallbackData{Row: 3, Col: 5}, "3,5"},
+		{CellCallbackData{Row: 0, Col: 0}, "0,0"},
+		{CellCallbackData{Row: maxSize - 1, Col: maxSize - 1}, last},
+		{CellCallbackData{Action: actionToggleMode}, "mode"},
+		{CellCallbackData{Game: "10:100", Row: 3, Col: 5}, "10:100|3,5"},
+		{CellCallbackData{Game: "10:100", Action: actionRestart}, "10:100|restart"},
+		{CellCallbackData{Game: game, Row: maxSize - 1, Col: maxSize - 1}, game + "|" + last},
+	}
+
+	for _, test := range tests {
+		encoded := encodeCallbackData(test.data)
+		if encoded != test.want {
+			t.Errorf("encodeCallbackData(%+v) = %q, want %q", test.data, encoded, test.want)
+		}
+
+		if len(encoded) > 64 {
+			t.Errorf("%q exceeds 64 bytes", encoded)
+		}
+
+		decoded, err := decodeCallbackData(encoded)
+		if err != nil || decoded != test.data {
+			t.Errorf("decodeCallbackData(%q) = %+v, %v, want %+v", encoded, decoded, err, test.data)
+		}
+	}
+}
+
+func TestDecodeCallbackData(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    CellCallbackData
+		wantErr bool
+	}{
+		{`{"row":3,"col":5}`, CellCallbackData{Row: 3, Col: 5}, false},
+		{`{"action":"mode","row":0,"col":0}`, CellCallbackData{Action: actionToggleMode}, false},
+		{`{"row":3`, CellCallbackData{}, true},
+		{"3,x", CellCallbackData{}, true},
+		{"unknown", CellCallbackData{}, true},
+		{"10:100|unknown", CellCallbackData{}, true},
+	}
+
+	for _, test := range tests {
+		got, err := decodeCallbackData(test.data)
+		if (err != nil) != test.wantErr {
+			t.Errorf("decodeCallbackData(%q) error = %v, want error %v", test.data, err, test.wantErr)
+			continue
+		}
+
+		if !test.wantErr && got != test.want {
+			t.Errorf("decodeCallbackData(%q) = %+v, want %+v", test.data, got, test.want)
+		}
+	}
+}
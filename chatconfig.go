@@ -0,0 +1,163 @@
+package main
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/floodcode/tbf"
+)
+
+const (
+	configDifficulty = "difficulty"
+	configTheme      = "theme"
+	configLanguage   = "lang"
+	configSingle     = "single"
+)
+
+const (
+	configOn  = "on"
+	configOff = "off"
+)
+
+// configAction shows defaults of the chat or changes one of them,
+// e.g. /config theme dark
+func configAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	shard := shardID(req.Bot)
+	chatID := req.Message.Chat.ID
+	args := commandArgs(req.Message.Text)
+	if len(args) == 0 {
+		current := chatSettings.Get(shard, chatID)
+		req.QuickMessageMD(tr(lang, "config.current",
+			configValue(lang, current.Difficulty),
+			configValue(lang, current.Theme),
+			configValue(lang, current.Language),
+			configSwitch(current.SingleGame),
+		))
+
+		return
+	}
+
+	if len(args) != 2 {
+		req.QuickMessageMD(tr(lang, "config.usage"))
+		return
+	}
+
+	field, value := strings.ToLower(args[0]), strings.ToLower(args[1])
+	update, allowed, ok := parseChatSetting(field, value)
+	if !ok {
+		if len(allowed) == 0 {
+			req.QuickMessageMD(tr(lang, "config.usage"))
+		} else {
+			req.QuickMessageMD(tr(lang, "config.invalid", field, strings.Join(allowed, ", ")))
+		}
+
+		return
+	}
+
+	if !canConfigure(req) {
+		req.QuickMessage(tr(lang, "config.not_admin"))
+		return
+	}
+
+	chatSettings.Update(shard, chatID, update)
+	req.QuickMessageMD(tr(lang, "config.set", field, value))
+}
+
+// canConfigure checks if the sender may change chat settings, in groups
+// only chat administrators can do that
+func canConfigure(req tbf.Request) bool {
+	chat := req.Message.Chat
+	if chat.Type == "private" {
+		return true
+	}
+
+	status, err := chatMemberStatus(req.Bot, chat.ID, req.Message.From.ID)
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to get chat member", "chat_id", chat.ID, "user_id", req.Message.From.ID, "error", err)
+		return false
+	}
+
+	return status == "administrator" || status == "creator"
+}
+
+// parseChatSetting returns update setting the field of chat settings to the value,
+// allowed values are returned instead when the value is invalid and nothing
+// is returned for unknown fields
+func parseChatSetting(field, value string) (func(settings *ChatSettings), []string, bool) {
+	switch field {
+	case configDifficulty:
+		if _, ok := difficulties[value]; !ok {
+			return nil, difficultyNames(), false
+		}
+
+		return func(settings *ChatSettings) { settings.Difficulty = value }, nil, true
+	case configTheme:
+		if _, ok := themes[value]; !ok {
+			return nil, themeNames(), false
+		}
+
+		return func(settings *ChatSettings) { settings.Theme = value }, nil, true
+	case configLanguage:
+		if _, ok := translations[value]; !ok {
+			return nil, languageNames(), false
+		}
+
+		return func(settings *ChatSettings) { settings.Language = value }, nil, true
+	case configSingle:
+		if value != configOn && value != configOff {
+			return nil, []string{configOn, configOff}, false
+		}
+
+		return func(settings *ChatSettings) { settings.SingleGame = value == configOn }, nil, true
+	}
+
+	return nil, nil, false
+}
+
+// applyChatSettings replaces settings of the game with defaults of its chat,
+// theme chosen by the owner takes precedence over chat's theme
+func applyChatSettings(game *Game) {
+	defaults := chatSettings.Get(game.Shard, game.ChatID)
+	if len(game.Theme) == 0 {
+		game.Theme = defaults.Theme
+	}
+
+	if len(defaults.Language) > 0 {
+		game.Language = defaults.Language
+	}
+}
+
+// chatDifficulty returns default difficulty of the chat if it's set
+func chatDifficulty(shard string, chatID int) (Difficulty, bool) {
+	difficulty, ok := difficulties[chatSettings.Get(shard, chatID).Difficulty]
+	return difficulty, ok
+}
+
+func configValue(lang, value string) string {
+	if len(value) == 0 {
+		return tr(lang, "config.unset")
+	}
+
+	return value
+}
+
+func configSwitch(value bool) string {
+	if value {
+		return configOn
+	}
+
+	return configOff
+}
+
+func languageNames() []string {
+	names := make([]string, 0, len(translations))
+	for name := range translations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/floodcode/tgbot"
+)
+
+func TestParseChatSetting(t *testing.T) {
+	tests := []struct {
+		field, value string
+		want         ChatSettings
+	}{
+		{configDifficulty, "hard", ChatSettings{Difficulty: "hard"}},
+		{configTheme, "dark", ChatSettings{Theme: "dark"}},
+		{configLanguage, "ru", ChatSettings{Language: "ru"}},
+		{configSingle, configOn, ChatSettings{SingleGame: true}},
+	}
+
+	for _, test := range tests {
+		store := newChatSettingsStore()
+		update, _, ok := parseChatSetting(test.field, test.value)
+		if !ok {
+			t.Errorf("parseChatSetting(%q, %q) rejected valid value", test.field, test.value)
+			continue
+		}
+
+		store.Update("", 10, update)
+		if got := store.Get("", 10); got != test.want {
+			t.Errorf("%s: settings = %+v, want %+v", test.field, got, test.want)
+		}
+
+		if got := store.Get("", 20); got != (ChatSettings{}) {
+			t.Errorf("%s: settings of another chat = %+v, want defaults", test.field, got)
+		}
+	}
+
+	store := newChatSettingsStore()
+	store.Update("", 10, func(settings *ChatSettings) { settings.SingleGame = true })
+	update, _, _ := parseChatSetting(configSingle, configOff)
+	store.Update("", 10, update)
+	if store.Get("", 10).SingleGame {
+		t.Error("single game is still on after setting it off")
+	}
+}
+
+func TestParseChatSettingInvalid(t *testing.T) {
+	tests := []struct {
+		field, value string
+		wantAllowed  bool
+	}{
+		{configDifficulty, "impossible", true},
+		{configTheme, "neon", true},
+		{configLanguage, "xx", true},
+		{configSingle, "maybe", true},
+		{"color", "red", false},
+	}
+
+	for _, test := range tests {
+		update, allowed, ok := parseChatSetting(test.field, test.value)
+		if ok || update != nil {
+			t.Errorf("parseChatSetting(%q, %q) accepted invalid value", test.field, test.value)
+		}
+
+		if (len(allowed) > 0) != test.wantAllowed {
+			t.Errorf("parseChatSetting(%q, %q) allowed values %v", test.field, test.value, allowed)
+		}
+	}
+}
+
+func TestConfigActionRequiresChatAdmin(t *testing.T) {
+	defer func(previous *ChatSettingsStore) { chatSettings = previous }(chatSettings)
+	previous := chatMemberStatus
+	defer func() { chatMemberStatus = previous }()
+
+	tests := []struct {
+		name     string
+		chatType string
+		status   string
+		wantSet  bool
+	}{
+		{"group member", "group", "member", false},
+		{"restricted", "supergroup", "restricted", false},
+		{"group admin", "group", "administrator", true},
+		{"group creator", "supergroup", "creator", true},
+		{"private chat", "private", "", true},
+	}
+
+	for _, test := range tests {
+		chatSettings = newChatSettingsStore()
+		chatMemberStatus = func(_ *tgbot.TelegramBot, chatID, userID int) (string, error) {
+			if test.chatType == "private" {
+				t.Errorf("%s: chat member requested in private chat", test.name)
+			}
+
+			return test.status, nil
+		}
+
+		req := testRequest(1, "/config theme dark")
+		req.Message.Chat = &tgbot.Chat{ID: -100, Type: test.chatType}
+		configAction(req)
+
+		if got := chatSettings.Get("", -100).Theme == "dark"; got != test.wantSet {
+			t.Errorf("%s: theme set = %v, want %v", test.name, got, test.wantSet)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	startDifficulty = "medium"
+)
+
+// Command contains bot command with its handler and usage lines shown in help,
+// commands without usage lines are hidden from help
+type Command struct {
+	Name   string
+	Action func(tbf.Request)
+	Usages []CommandUsage
+}
+
+// CommandUsage contains arguments of the command and translation key of their description
+type CommandUsage struct {
+	Args string
+	Key  string
+}
+
+func botCommands() []Command {
+	return []Command{
+		{Name: "start", Action: startAction},
+		{Name: "help", Action: helpAction, Usages: []CommandUsage{
+			{Key: "help.help"},
+		}},
+		{Name: "play", Action: playAction, Usages: []CommandUsage{
+			{Key: "help.play"},
+			{Args: strings.Join(difficultyNames(), "|"), Key: "help.play_preset"},
+			{Args: "<width> <height> <mines>", Key: "help.play_custom"},
+			{Args: "... " + seedPrefix + "<number>", Key: "help.play_seed"},
+			{Args: "... " + noGuessArg, Key: "help.play_noguess"},
+			{Args: "... " + manualArg, Key: "help.play_manual"},
+		}},
+		{Name: "duel", Action: duelAction, Usages: []CommandUsage{
+			{Key: "help.duel"},
+		}},
+		{Name: "daily", Action: dailyAction, Usages: []CommandUsage{
+			{Key: "help.daily"},
+			{Args: "top", Key: "help.daily_top"},
+		}},
+		{Name: "train", Action: trainAction, Usages: []CommandUsage{
+			{Key: "help.train"},
+			{Args: strings.Join(trainingNames(), "|"), Key: "help.train_name"},
+		}},
+		{Name: "demo", Action: demoAction, Usages: []CommandUsage{
+			{Args: "[" + strings.Join(difficultyNames(), "|") + "]", Key: "help.demo"},
+		}},
+		{Name: "flag", Action: flagAction, Usages: []CommandUsage{
+			{Key: "help.flag"},
+		}},
+		{Name: "open", Action: openAction, Usages: []CommandUsage{
+			{Args: "<row> <column>", Key: "help.open"},
+		}},
+		{Name: "hint", Action: hintAction, Usages: []CommandUsage{
+			{Key: "help.hint"},
+		}},
+		{Name: "analyze", Action: analyzeAction, Usages: []CommandUsage{
+			{Key: "help.analyze"},
+		}},
+		{Name: "undo", Action: undoAction, Usages: []CommandUsage{
+			{Key: "help.undo"},
+		}},
+		{Name: "board", Action: boardAction, Usages: []CommandUsage{
+			{Key: "help.board"},
+		}},
+		{Name: "watch", Action: watchAction, Usages: []CommandUsage{
+			{Key: "help.watch"},
+		}},
+		{Name: "replay", Action: replayAction, Usages: []CommandUsage{
+			{Key: "help.replay"},
+			{Args: "<n>", Key: "help.replay_n"},
+		}},
+		{Name: "export", Action: exportAction, Usages: []CommandUsage{
+			{Key: "help.export"},
+		}},
+		{Name: "cancel", Action: cancelAction, Usages: []CommandUsage{
+			{Key: "help.cancel"},
+		}},
+		{Name: "abandonall", Action: abandonAllAction, Usages: []CommandUsage{
+			{Key: "help.abandonall"},
+		}},
+		{Name: "restart", Action: restartAction, Usages: []CommandUsage{
+			{Key: "help.restart"},
+		}},
+		{Name: "scoreboard", Action: scoreboardAction, Usages: []CommandUsage{
+			{Key: "help.scoreboard"},
+		}},
+		{Name: "leaderboard", Action: leaderboardAction},
+		{Name: "share", Action: shareAction, Usages: []CommandUsage{
+			{Args: "[n]", Key: "help.share"},
+		}},
+		{Name: "import", Action: importAction, Usages: []CommandUsage{
+			{Args: "<code>", Key: "help.import"},
+		}},
+		{Name: "games", Action: gamesAction, Usages: []CommandUsage{
+			{Key: "help.games"},
+		}},
+		{Name: "stats", Action: statsAction, Usages: []CommandUsage{
+			{Key: "help.stats"},
+		}},
+		{Name: "globalstats", Action: globalStatsAction, Usages: []CommandUsage{
+			{Key: "help.globalstats"},
+		}},
+		{Name: "theme", Action: themeAction, Usages: []CommandUsage{
+			{Args: strings.Join(themeNames(), "|"), Key: "help.theme"},
+		}},
+		{Name: "themes", Action: themesAction, Usages: []CommandUsage{
+			{Key: "help.themes"},
+		}},
+		{Name: "setglyph", Action: setGlyphAction, Usages: []CommandUsage{
+			{Args: "mine|flag <emoji>", Key: "help.setglyph"},
+		}},
+		{Name: "config", Action: configAction, Usages: []CommandUsage{
+			{Key: "help.config"},
+			{Args: configDifficulty + " <" + strings.Join(difficultyNames(), "|") + ">", Key: "help.config_diff"},
+			{Args: configTheme + " <" + strings.Join(themeNames(), "|") + ">", Key: "help.config_theme"},
+			{Args: configLanguage + " <" + strings.Join(languageNames(), "|") + ">", Key: "help.config_lang"},
+			{Args: configSingle + " <" + configOn + "|" + configOff + ">", Key: "help.config_one"},
+		}},
+		{Name: "admin", Action: adminAction},
+		{Name: "setdelay", Action: setDelayAction},
+		{Name: "version", Action: versionAction, Usages: []CommandUsage{
+			{Key: "help.version"},
+		}},
+		{Name: "assist", Action: assistAction, Usages: []CommandUsage{
+			{Key: "help.assist"},
+		}},
+		{Name: "verbose", Action: verboseAction, Usages: []CommandUsage{
+			{Key: "help.verbose"},
+		}},
+	}
+}
+
+// startAction sends onboarding message explaining the game,
+// its text can be replaced with welcome_text config field
+func startAction(req tbf.Request) {
+	if watchID, ok := watchPayload(req.Message.Text); ok {
+		startWatching(req, watchID)
+		return
+	}
+
+	lang := userLanguage(req.Message.From)
+	text := botConfig.WelcomeText
+	if len(text) == 0 {
+		text = strings.Join([]string{
+			tr(lang, "start.welcome"),
+			tr(lang, "start.open"),
+			tr(lang, "start.flag"),
+			tr(lang, "start.help"),
+		}, "\n\n")
+	}
+
+	_, err := req.Bot.SendMessage(tgbot.SendMessageConfig{
+		ChatID: tgbot.ChatID(req.Message.Chat.ID),
+		Text:   text,
+		ReplyMarkup: tgbot.InlineKeyboardMarkup([][]tgbot.InlineKeyboardButton{{{
+			Text: tr(lang, "start.play", startDifficulty),
+			CallbackData: encodeCallbackData(CellCallbackData{
+				Action: actionPlay,
+				Value:  startDifficulty,
+			}),
+		}}}),
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send welcome message", "chat_id", req.Message.Chat.ID, "error", err)
+	}
+}
+
+func helpAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	lines := []string{tr(lang, "help.title")}
+	for _, command := range botCommands() {
+		for _, usage := range command.Usages {
+			line := "/" + command.Name
+			if len(usage.Args) > 0 {
+				line += " " + usage.Args
+			}
+
+			lines = append(lines, escapeMarkdown(line+" - "+tr(lang, usage.Key)))
+		}
+	}
+
+	req.QuickMessageMD(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	configPath   = "config.json"
+	defaultDelay = 300
+
+	defaultMaxDensity   = 0.8
+	defaultReadyTimeout = 600
+)
+
+const (
+	minPollBackoff     = time.Second
+	defaultPollBackoff = time.Minute
+)
+
+const (
+	modePoll    = "poll"
+	modeWebhook = "webhook"
+)
+
+// BotConfig contains bot's environment variables
+type BotConfig struct {
+	Token           string        `json:"token"`
+	Tokens          []string      `json:"tokens"`
+	Mode            string        `json:"mode"`
+	Delay           int           `json:"delay"`
+	PollMaxBackoff  int           `json:"poll_max_backoff"`
+	Webhook         WebhookConfig `json:"webhook"`
+	GameTTL         int           `json:"game_ttl"`
+	IdleTimeout     int           `json:"idle_timeout"`
+	StatePath       string        `json:"state_path"`
+	SaveInterval    int           `json:"save_interval"`
+	LockedGames     bool          `json:"locked_games"`
+	CompactBoard    bool          `json:"compact_board"`
+	TextBoard       bool          `json:"text_board"`
+	MaxGames        int           `json:"max_games"`
+	SingleGame      bool          `json:"single_game"`
+	RateLimit       float64       `json:"rate_limit"`
+	RateBurst       int           `json:"rate_burst"`
+	MetricsAddr     string        `json:"metrics_addr"`
+	Telemetry       bool          `json:"telemetry"`
+	HealthAddr      string        `json:"health_addr"`
+	ReadyTimeout    int           `json:"ready_timeout"`
+	AnimateFlood    bool          `json:"animate_flood"`
+	FloodLimit      int           `json:"flood_limit"`
+	MinFirstReveal  int           `json:"min_first_reveal"`
+	Admins          []int         `json:"admins"`
+	WelcomeText     string        `json:"welcome_text"`
+	ConfirmFirstTap bool          `json:"confirm_first_tap"`
+	AlertEvents     bool          `json:"alert_events"`
+	MinDensity      float64       `json:"min_density"`
+	MaxDensity      float64       `json:"max_density"`
+	LogLevel        string        `json:"log_level"`
+}
+
+// WebhookConfig contains settings used to receive updates via webhook
+type WebhookConfig struct {
+	ListenAddr string `json:"listen_addr"`
+	URL        string `json:"url"`
+	CertPath   string `json:"cert_path"`
+	KeyPath    string `json:"key_path"`
+}
+
+// loadConfig reads config file and falls back to BOT_TOKEN and BOT_DELAY
+// environment variables when the file is missing
+func loadConfig(path string) (BotConfig, error) {
+	config := BotConfig{
+		Delay:        defaultDelay,
+		MaxDensity:   defaultMaxDensity,
+		ReadyTimeout: defaultReadyTimeout,
+	}
+
+	configData, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		err = loadEnvConfig(&config)
+		if err != nil {
+			return config, err
+		}
+	} else if err != nil {
+		return config, err
+	} else if err = decodeConfig(configData, &config); err != nil {
+		return config, fmt.Errorf("%s: %v", path, describeJSONError(err))
+	}
+
+	if len(config.Token) == 0 {
+		config.Token = os.Getenv("BOT_TOKEN")
+	}
+
+	return config, validateConfig(config)
+}
+
+// decodeConfig unmarshals config rejecting unknown fields, they are
+// most likely misspelled names of known ones
+func decodeConfig(data []byte, config *BotConfig) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
+
+func validateConfig(config BotConfig) error {
+	if len(config.Token) == 0 && len(config.Tokens) == 0 {
+		return errors.New("Bot token is not set in config file or BOT_TOKEN variable")
+	}
+
+	if config.Delay < 0 {
+		return errors.New("Delay should not be negative")
+	}
+
+	if config.ReadyTimeout < 0 {
+		return errors.New("Ready timeout should not be negative")
+	}
+
+	if config.FloodLimit < 0 {
+		return errors.New("Flood limit should not be negative")
+	}
+
+	if config.MinFirstReveal < 0 {
+		return errors.New("Minimal first reveal should not be negative")
+	}
+
+	// at least one cell should be free of mines, otherwise the first tap
+	// has no safe cell to move the mine to
+	if config.MinDensity < 0 || config.MaxDensity >= 1 || config.MinDensity >= config.MaxDensity {
+		return errors.New("Mine density bounds should satisfy 0 <= min_density < max_density < 1")
+	}
+
+	if len(config.AllTokens()) > 1 && config.Mode == modeWebhook {
+		return errors.New("Multiple tokens are supported only in poll mode")
+	}
+
+	return nil
+}
+
+// AllTokens returns token followed by additional tokens without duplicates
+func (c BotConfig) AllTokens() []string {
+	var tokens []string
+	for _, token := range append([]string{c.Token}, c.Tokens...) {
+		if len(token) > 0 && !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+func describeJSONError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Errorf("field %q should be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Errorf("unknown field %s, check its spelling", field)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("invalid JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)
+	}
+
+	return err
+}
+
+func setupLogger(level string) error {
+	var logLevel slog.Level
+	if len(level) > 0 {
+		if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("Unknown log level %q", level)
+		}
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: logLevel,
+	})))
+
+	return nil
+}
+
+func loadEnvConfig(config *BotConfig) error {
+	config.Token = os.Getenv("BOT_TOKEN")
+
+	delay, ok := os.LookupEnv("BOT_DELAY")
+	if !ok {
+		return nil
+	}
+
+	var err error
+	config.Delay, err = strconv.Atoi(delay)
+	if err != nil {
+		return errors.New("BOT_DELAY should be a number")
+	}
+
+	return nil
+}
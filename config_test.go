@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() BotConfig {
+		return BotConfig{
+			Token:        "123:token",
+			Delay:        defaultDelay,
+			MaxDensity:   defaultMaxDensity,
+			ReadyTimeout: defaultReadyTimeout,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*BotConfig)
+		wantErr bool
+	}{
+		{"defaults", func(c *BotConfig) {}, false},
+		{"no token", func(c *BotConfig) { c.Token = "" }, true},
+		{"tokens only", func(c *BotConfig) { c.Token, c.Tokens = "", []string{"123:token"} }, false},
+		{"negative delay", func(c *BotConfig) { c.Delay = -1 }, true},
+		{"large delay", func(c *BotConfig) { c.Delay = 60000 }, false},
+		{"negative ready timeout", func(c *BotConfig) { c.ReadyTimeout = -1 }, true},
+		{"negative flood limit", func(c *BotConfig) { c.FloodLimit = -1 }, true},
+		{"negative first reveal", func(c *BotConfig) { c.MinFirstReveal = -1 }, true},
+		{"negative min density", func(c *BotConfig) { c.MinDensity = -0.1 }, true},
+		{"full max density", func(c *BotConfig) { c.MaxDensity = 1 }, true},
+		{"almost full max density", func(c *BotConfig) { c.MaxDensity = 0.99 }, false},
+		{"min density above max", func(c *BotConfig) { c.MinDensity = 0.9 }, true},
+		{"webhook with tokens", func(c *BotConfig) {
+			c.Mode, c.Tokens = modeWebhook, []string{"456:token"}
+		}, true},
+	}
+
+	for _, test := range tests {
+		config := valid()
+		test.modify(&config)
+		err := validateConfig(config)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: validateConfig() = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestLoadConfigMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"valid", `{"token": "123:token", "delay": 500}`, ""},
+		{"wrong type", `{"token": "123:token", "delay": "500"}`, `field "delay" should be int, got string`},
+		{"misspelled field", `{"token": "123:token", "dealy": 500}`, `unknown field "dealy", check its spelling`},
+		{"trailing comma", `{"token": "123:token",}`, "invalid JSON at offset"},
+		{"invalid value", `{"token": "123:token", "delay": -5}`, "Delay should not be negative"},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(test.data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := loadConfig(path)
+		if len(test.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: loadConfig() = %v, want nil", test.name, err)
+			}
+
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: loadConfig() = %v, want error containing %q", test.name, err, test.want)
+		}
+	}
+}
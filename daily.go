@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/floodcode/tbf"
+)
+
+const (
+	dailyDifficulty = "medium"
+	dailyDateFormat = "2006-01-02"
+)
+
+// DailyResult contains result of a single player in the daily challenge
+type DailyResult struct {
+	UserID  int           `json:"user_id"`
+	Name    string        `json:"name"`
+	Won     bool          `json:"won"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// DailyResults contains first results of players for each day
+// and can be safely used from multiple goroutines
+type DailyResults struct {
+	mu      sync.RWMutex
+	results map[string][]DailyResult
+}
+
+func newDailyResults() *DailyResults {
+	return &DailyResults{
+		results: map[string][]DailyResult{},
+	}
+}
+
+// Add records result of the player for the day unless they already have one
+func (d *DailyResults) Add(date string, result DailyResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, existing := range d.results[date] {
+		if existing.UserID == result.UserID {
+			return
+		}
+	}
+
+	d.results[date] = append(d.results[date], result)
+}
+
+// Top returns up to n fastest wins of the day
+func (d *DailyResults) Top(date string, n int) []DailyResult {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var wins []DailyResult
+	for _, result := range d.results[date] {
+		if result.Won {
+			wins = append(wins, result)
+		}
+	}
+
+	sort.Slice(wins, func(i, j int) bool {
+		return wins[i].Elapsed < wins[j].Elapsed
+	})
+
+	if len(wins) > n {
+		wins = wins[:n]
+	}
+
+	return wins
+}
+
+// Snapshot returns copy of results of all days
+func (d *DailyResults) Snapshot() map[string][]DailyResult {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	snapshot := make(map[string][]DailyResult, len(d.results))
+	for date, results := range d.results {
+		snapshot[date] = append([]DailyResult(nil), results...)
+	}
+
+	return snapshot
+}
+
+// Load replaces results of all days with given ones
+func (d *DailyResults) Load(results map[string][]DailyResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.results = make(map[string][]DailyResult, len(results))
+	for date, dayResults := range results {
+		d.results[date] = dayResults
+	}
+}
+
+// dailySeed returns seed of the daily board for the date like 20240131
+func dailySeed(date string) int64 {
+	seed, _ := strconv.ParseInt(strings.ReplaceAll(date, "-", ""), 10, 64)
+	return seed
+}
+
+func today() string {
+	return time.Now().UTC().Format(dailyDateFormat)
+}
+
+func dailyAction(req tbf.Request) {
+	args := commandArgs(req.Message.Text)
+	if len(args) > 0 && strings.ToLower(args[0]) == "top" {
+		dailyTopAction(req)
+		return
+	}
+
+	if gameLimitReached(req) {
+		return
+	}
+
+	date := today()
+	difficulty := difficulties[dailyDifficulty]
+	game := newGame(req.Message.From, newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, dailySeed(date)))
+	game.Daily = date
+	openDailyStart(game.Minefield)
+
+	startGame(req.Bot, req.Message.Chat.ID, game)
+}
+
+// openDailyStart opens the cell picked by the seed, preferably an empty one.
+// Mines are moved away from the first opened cell, so the daily board is
+// the same for every player only when they all start from the same cell.
+// The cell isn't recorded in history, so it can't be undone
+func openDailyStart(minefield *Minefield) {
+	var cells, empty []int
+	for index := 0; index < minefield.Width*minefield.Height; index++ {
+		cells = append(cells, index)
+		if minefield.Field[index/minefield.Width][index%minefield.Width].Type == TypeEmpty {
+			empty = append(empty, index)
+		}
+	}
+
+	if len(empty) > 0 {
+		cells = empty
+	}
+
+	index := cells[rand.New(rand.NewSource(minefield.Seed)).Intn(len(cells))]
+	minefield.open(index/minefield.Width, index%minefield.Width)
+}
+
+func dailyTopAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	date := today()
+	results := dailyResults.Top(date, scoreboardSize)
+	if len(results) == 0 {
+		req.QuickMessage(tr(lang, "daily.empty"))
+		return
+	}
+
+	lines := []string{"*" + tr(lang, "daily.title", date) + "*"}
+	for i, result := range results {
+		lines = append(lines, fmt.Sprintf("%d. %s — %s", i+1, escapeMarkdown(result.Name), result.Elapsed))
+	}
+
+	req.QuickMessageMD(strings.Join(lines, "\n"))
+}
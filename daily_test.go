@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func mineLayout(m *Minefield) []bool {
+	var layout []bool
+	for _, cells := range m.Field {
+		for _, cell := range cells {
+			layout = append(layout, cell.Type == TypeMine)
+		}
+	}
+
+	return layout
+}
+
+func TestDailyBoardSameForEveryone(t *testing.T) {
+	difficulty := difficulties[dailyDifficulty]
+	seed := dailySeed("2024-01-31")
+	var layouts [][]bool
+	for _, tap := range [][2]int{{0, 0}, {difficulty.Height - 1, difficulty.Width - 1}} {
+		minefield := newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, seed)
+		openDailyStart(minefield)
+		if minefield.countState(StateOpened) == 0 {
+			t.Fatal("daily start cell is not opened")
+		}
+
+		minefield.Open(tap[0], tap[1])
+		layouts = append(layouts, mineLayout(minefield))
+	}
+
+	for i := range layouts[0] {
+		if layouts[0][i] != layouts[1][i] {
+			t.Fatalf("mine layouts differ at cell %d", i)
+		}
+	}
+}
+
+func TestDailyStartCantBeUndone(t *testing.T) {
+	difficulty := difficulties[dailyDifficulty]
+	minefield := newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, dailySeed("2024-01-31"))
+	openDailyStart(minefield)
+	opened := minefield.countState(StateOpened)
+	if opened == 0 {
+		t.Fatal("daily start cell is not opened")
+	}
+
+	if len(minefield.History) != 0 || len(minefield.Moves) != 0 {
+		t.Errorf("daily start recorded as %d moves and %d taps, want none", len(minefield.History), len(minefield.Moves))
+	}
+
+	if minefield.Undo() {
+		t.Error("Undo() right after daily start = true, want false")
+	}
+
+	if got := minefield.countState(StateOpened); got != opened {
+		t.Errorf("%d cells opened after Undo(), want %d", got, opened)
+	}
+
+	// replay starts from the board with the start cell opened
+	row, col, _ := minefield.SafeCell()
+	minefield.Open(row, col)
+	replayed := replayGame(newReplay(&Game{Minefield: minefield}))
+	if got := replayed.Minefield.countState(StateOpened); got != opened {
+		t.Errorf("replay starts with %d opened cells, want %d", got, opened)
+	}
+}
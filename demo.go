@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	demoDifficulty = "medium"
+	maxDemoMoves   = 100
+	demoMoveDelay  = time.Second
+)
+
+// demoAction sends read-only board which plays itself by opening the safest
+// cell found by the solver, /demo <difficulty> picks size of the board
+func demoAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	name := demoDifficulty
+	if args := commandArgs(req.Message.Text); len(args) > 0 {
+		name = strings.ToLower(args[0])
+	}
+
+	difficulty, ok := difficulties[name]
+	if !ok {
+		req.QuickMessage(tr(lang, "demo.unknown", strings.Join(difficultyNames(), ", ")))
+		return
+	}
+
+	game := newGame(req.Message.From, newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, newSeed()))
+	game.ChatID = req.Message.Chat.ID
+	game.Shard = shardID(req.Bot)
+	msg, err := sendMessage(req.Bot, tgbot.SendMessageConfig{
+		ChatID:      tgbot.ChatID(game.ChatID),
+		Text:        renderText(game, tr(game.Language, "demo.title")),
+		ReplyMarkup: renderMirror(game),
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send demo board", "chat_id", game.ChatID, "error", err)
+		return
+	}
+
+	game.MessageID = msg.MessageID
+	go playDemo(req.Bot, game, req.Message.From.ID)
+}
+
+// playDemo makes moves of the demo game editing its board after each one,
+// moves wait while demos of the user who started this one are rate limited
+// so only made moves count towards maxDemoMoves
+func playDemo(bot *tgbot.TelegramBot, game *Game, userID int) {
+	minefield := game.Minefield
+	for move := 0; move < maxDemoMoves && minefield.State == GameRunning; move++ {
+		time.Sleep(demoMoveDelay)
+		for demoLimiter != nil && !demoLimiter.Allow(userID) {
+			time.Sleep(demoMoveDelay)
+		}
+
+		if !demoMove(minefield) {
+			break
+		}
+
+		title := tr(game.Language, "demo.title")
+		switch minefield.State {
+		case GameWin:
+			title = tr(game.Language, "demo.won")
+		case GameLose:
+			title = tr(game.Language, "demo.lost")
+		}
+
+		config := game.EditConfig()
+		config.Text = renderText(game, title)
+		config.ReplyMarkup = renderMirror(game)
+		if err := editMessage(bot, config); err != nil {
+			slog.Error("Unable to update demo board", "game", game.Key(), "error", err)
+			return
+		}
+	}
+
+	slog.Debug("Demo finished", "game", game.Key(), "state", minefield.State)
+}
+
+// demoMove opens the safest cell of the demo board and flags obvious mines,
+// false is returned when there is no cell to open
+func demoMove(minefield *Minefield) bool {
+	row, col, _, _, ok := minefield.SafestCell()
+	if !ok {
+		return false
+	}
+
+	minefield.Open(row, col)
+	minefield.AutoFlag()
+	return true
+}
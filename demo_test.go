@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestDemoPlaysToCompletion(t *testing.T) {
+	for _, name := range difficultyNames() {
+		difficulty := difficulties[name]
+		for seed := int64(1); seed <= 10; seed++ {
+			minefield := newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, seed)
+			moves := 0
+			for moves < maxDemoMoves && minefield.State == GameRunning && demoMove(minefield) {
+				moves++
+			}
+
+			if minefield.State == GameRunning {
+				t.Errorf("%s board with seed %d is still running after %d moves", name, seed, moves)
+			}
+		}
+	}
+}
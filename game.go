@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/floodcode/tgbot"
+)
+
+// Game contains minefield with its per-game settings,
+// mu should be held while game is changed and its board is updated
+type Game struct {
+	mu sync.Mutex
+
+	// signature identifies content of the board message after the last edit
+	signature string
+
+	Minefield       *Minefield `json:"minefield"`
+	ChatID          int        `json:"chat_id,omitempty"`
+	MessageID       int        `json:"message_id,omitempty"`
+	InlineMessageID string     `json:"inline_message_id,omitempty"`
+	OwnerID         int        `json:"owner_id"`
+	Theme           string     `json:"theme"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastMoveAt      time.Time  `json:"last_move_at,omitempty"`
+	Language        string     `json:"language"`
+	FlagMode        bool       `json:"flag_mode"`
+	HintsUsed       int        `json:"hints_used"`
+	GaveUp          bool       `json:"gave_up,omitempty"`
+	Daily           string     `json:"daily,omitempty"`
+	Training        string     `json:"training,omitempty"`
+	Shard           string     `json:"shard,omitempty"`
+	WatchID         string     `json:"watch_id,omitempty"`
+	Mirrors         []Mirror   `json:"mirrors,omitempty"`
+	TextMessageID   int        `json:"text_message_id,omitempty"`
+
+	// Viewport contains top left cell of the visible window of large boards
+	Viewport Position `json:"viewport,omitempty"`
+
+	// PendingTap contains first tapped cell waiting for confirmation
+	PendingTap *Position `json:"pending_tap,omitempty"`
+
+	// Players contains participants of two-player game taking turns,
+	// it is empty for regular games
+	Players []Player `json:"players,omitempty"`
+	Turn    int      `json:"turn,omitempty"`
+}
+
+// Position contains coordinates of a minefield cell
+type Position struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Player contains participant of two-player game and count of safe cells opened by them
+type Player struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Revealed int    `json:"revealed"`
+}
+
+// newGame creates game owned by the user with their settings applied
+func newGame(owner *tgbot.User, minefield *Minefield) *Game {
+	minefield.FloodLimit = botConfig.FloodLimit
+	minefield.MinReveal = botConfig.MinFirstReveal
+	return &Game{
+		Minefield: minefield,
+		OwnerID:   owner.ID,
+		Theme:     settings.Get(owner.ID).Theme,
+		Language:  userLanguage(owner),
+		CreatedAt: time.Now(),
+		WatchID:   newWatchID(),
+	}
+}
+
+// MarshalJSON encodes game holding its lock
+func (g *Game) MarshalJSON() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	type game Game
+	return json.Marshal((*game)(g))
+}
+
+// LastActivity returns time of the last move or creation time if there were no moves
+func (g *Game) LastActivity() time.Time {
+	if g.LastMoveAt.After(g.CreatedAt) {
+		return g.LastMoveAt
+	}
+
+	return g.CreatedAt
+}
+
+// Duel checks if players of the game take turns
+func (g *Game) Duel() bool {
+	return len(g.Players) > 1
+}
+
+// CurrentPlayer returns player whose turn it is
+func (g *Game) CurrentPlayer() *Player {
+	return &g.Players[g.Turn]
+}
+
+// Key returns identifier of the game board message used as game store key
+func (g *Game) Key() string {
+	if len(g.InlineMessageID) > 0 {
+		return inlineKey(g.InlineMessageID)
+	}
+
+	return shardKey(g.Shard, messageKey(g.ChatID, g.MessageID))
+}
+
+// Playable reports whether the user can make moves in the game, games of
+// locked_games mode are played only by their owner and duel players
+func (g *Game) Playable(userID int) bool {
+	if !botConfig.LockedGames || userID == g.OwnerID {
+		return true
+	}
+
+	return slices.ContainsFunc(g.Players, func(player Player) bool {
+		return player.ID == userID
+	})
+}
+
+// EditConfig returns config to edit game board message
+func (g *Game) EditConfig() tgbot.EditMessageTextConfig {
+	if len(g.InlineMessageID) > 0 {
+		return tgbot.EditMessageTextConfig{
+			InlineMessageID: g.InlineMessageID,
+		}
+	}
+
+	return tgbot.EditMessageTextConfig{
+		ChatID:    tgbot.ChatID(g.ChatID),
+		MessageID: g.MessageID,
+	}
+}
+
+// boardSignature returns hash of board message content used to skip edits
+// which don't change anything
+func boardSignature(text string, markup *tgbot.ReplyMarkup) string {
+	markupData, _ := json.Marshal(markup)
+	hash := sha256.Sum256(append([]byte(text+"\x00"), markupData...))
+	return hex.EncodeToString(hash[:])
+}
+
+func messageKey(chatID, messageID int) string {
+	return fmt.Sprintf("%d:%d", chatID, messageID)
+}
+
+// shardKey prefixes key with shard ID as chat and message IDs
+// are only unique within a single bot
+func shardKey(shard, key string) string {
+	if len(shard) == 0 {
+		return key
+	}
+
+	return shard + "/" + key
+}
+
+func chatKey(shard string, chatID int) string {
+	return shardKey(shard, strconv.Itoa(chatID))
+}
+
+func inlineKey(inlineMessageID string) string {
+	return "inline:" + inlineMessageID
+}
+
+func callbackKey(shard string, query *tgbot.CallbackQuery) string {
+	if query.Message != nil {
+		return shardKey(shard, messageKey(query.Message.Chat.ID, query.Message.MessageID))
+	}
+
+	return inlineKey(query.InlineMessageID)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLockedGame(t *testing.T) {
+	single := &Game{OwnerID: 1}
+	duel := &Game{OwnerID: 1, Players: []Player{{ID: 1}, {ID: 2}}}
+	tests := []struct {
+		name   string
+		locked bool
+		game   *Game
+		userID int
+		want   bool
+	}{
+		{"unlocked owner", false, single, 1, true},
+		{"unlocked other user", false, single, 2, true},
+		{"locked owner", true, single, 1, true},
+		{"locked other user", true, single, 2, false},
+		{"locked duel opponent", true, duel, 2, true},
+		{"locked duel stranger", true, duel, 3, false},
+	}
+
+	for _, test := range tests {
+		withConfig(t, BotConfig{LockedGames: test.locked})
+		if got := test.game.Playable(test.userID); got != test.want {
+			t.Errorf("%s: Playable(%d) = %v, want %v", test.name, test.userID, got, test.want)
+		}
+	}
+}
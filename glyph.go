@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/floodcode/tbf"
+)
+
+const (
+	glyphMine = "mine"
+	glyphFlag = "flag"
+
+	// maxGlyphBytes keeps custom glyphs close to size of theme ones,
+	// so boards stay within keyboard size budget
+	maxGlyphBytes = 16
+)
+
+// withGlyphs returns copy of the theme with glyphs replaced by user's ones
+func (t Theme) withGlyphs(glyphs map[string]string) Theme {
+	if len(glyphs) == 0 {
+		return t
+	}
+
+	types := make(map[int]string, len(t.Types))
+	for cellType, glyph := range t.Types {
+		types[cellType] = glyph
+	}
+
+	t.Types = types
+	if glyph, ok := glyphs[glyphMine]; ok {
+		t.Types[TypeMine] = glyph
+	}
+
+	if glyph, ok := glyphs[glyphFlag]; ok {
+		t.Flagged = glyph
+	}
+
+	return t
+}
+
+// gameTheme returns theme of the game with glyphs of its owner applied
+func gameTheme(game *Game) Theme {
+	return getTheme(game.Theme).withGlyphs(settings.Get(game.OwnerID).Glyphs)
+}
+
+// singleGrapheme checks if the text is a single visible character or emoji,
+// it may be followed by variation selectors, skin tones, keycap mark or
+// joined with other emoji by zero width joiner
+func singleGrapheme(text string) bool {
+	if len(text) == 0 || len(text) > maxGlyphBytes || !utf8.ValidString(text) {
+		return false
+	}
+
+	runes := []rune(text)
+	if !unicode.IsGraphic(runes[0]) || unicode.IsSpace(runes[0]) || unicode.Is(unicode.Mn, runes[0]) {
+		return false
+	}
+
+	// flags are pairs of regional indicators
+	if isRegionalIndicator(runes[0]) {
+		return len(runes) == 2 && isRegionalIndicator(runes[1])
+	}
+
+	for i := 1; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\u200d':
+			// joined emoji should follow joiner
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				return false
+			}
+
+			i++
+		case unicode.Is(unicode.Variation_Selector, r), r == '\u20e3',
+			r >= 0x1f3fb && r <= 0x1f3ff, r >= 0xe0020 && r <= 0xe007f:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// setGlyphAction replaces mine or flag glyph in boards of the user's games,
+// /setglyph mine resets the glyph to the theme's one
+func setGlyphAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	args := commandArgs(req.Message.Text)
+	if len(args) == 0 || len(args) > 2 {
+		req.QuickMessage(tr(lang, "glyph.usage"))
+		return
+	}
+
+	kind := strings.ToLower(args[0])
+	if kind != glyphMine && kind != glyphFlag {
+		req.QuickMessage(tr(lang, "glyph.usage"))
+		return
+	}
+
+	if len(args) == 1 {
+		settings.Update(req.Message.From.ID, func(s *UserSettings) {
+			s.Glyphs = withGlyph(s.Glyphs, kind, "")
+		})
+
+		req.QuickMessage(tr(lang, "glyph.reset", kind))
+		return
+	}
+
+	glyph := args[1]
+	if !singleGrapheme(glyph) {
+		req.QuickMessage(tr(lang, "glyph.invalid"))
+		return
+	}
+
+	settings.Update(req.Message.From.ID, func(s *UserSettings) {
+		s.Glyphs = withGlyph(s.Glyphs, kind, glyph)
+	})
+
+	req.QuickMessage(tr(lang, "glyph.set", kind, glyph))
+}
+
+// withGlyph returns copy of the glyphs with the glyph set, empty glyph removes it
+func withGlyph(glyphs map[string]string, kind, glyph string) map[string]string {
+	updated := make(map[string]string, len(glyphs)+1)
+	for k, v := range glyphs {
+		updated[k] = v
+	}
+
+	if len(glyph) == 0 {
+		delete(updated, kind)
+	} else {
+		updated[kind] = glyph
+	}
+
+	if len(updated) == 0 {
+		return nil
+	}
+
+	return updated
+}
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestSingleGrapheme(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"X", true},
+		{"💣", true},
+		{"⚠️", true},
+		{"👍🏽", true},
+		{"🇺🇦", true},
+		{"1️⃣", true},
+		{"👩‍🚒", true},
+		{"", false},
+		{"ab", false},
+		{"💣💣", false},
+		{" ", false},
+		{"́", false},
+		{"🇺", false},
+		{"👩‍", false},
+		{"\xff", false},
+		{"👨‍👩‍👧‍👦👨‍👩‍👧‍👦", false},
+	}
+
+	for _, test := range tests {
+		if got := singleGrapheme(test.text); got != test.want {
+			t.Errorf("singleGrapheme(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestGlyphOverride(t *testing.T) {
+	theme := getTheme(defaultTheme)
+	tests := []struct {
+		kind        string
+		glyph       string
+		wantMine    string
+		wantFlagged string
+	}{
+		{glyphMine, "🐙", "🐙", theme.Flagged},
+		{glyphFlag, "📍", theme.Types[TypeMine], "📍"},
+	}
+
+	for _, test := range tests {
+		glyphs := withGlyph(nil, test.kind, test.glyph)
+		custom := theme.withGlyphs(glyphs)
+		if custom.Types[TypeMine] != test.wantMine || custom.Flagged != test.wantFlagged {
+			t.Errorf("%s: mine %q and flag %q, want %q and %q",
+				test.kind, custom.Types[TypeMine], custom.Flagged, test.wantMine, test.wantFlagged)
+		}
+
+		if custom.Closed != theme.Closed || custom.Question != theme.Question || custom.Exploded != theme.Exploded {
+			t.Errorf("%s: other glyphs changed", test.kind)
+		}
+
+		for cellType, glyph := range theme.Types {
+			if cellType != TypeMine && custom.Types[cellType] != glyph {
+				t.Errorf("%s: glyph of type %d changed to %q", test.kind, cellType, custom.Types[cellType])
+			}
+		}
+
+		if reset := withGlyph(glyphs, test.kind, ""); len(reset) != 0 {
+			t.Errorf("%s: glyphs after reset = %v, want none", test.kind, reset)
+		}
+	}
+
+	if themes[defaultTheme].Types[TypeMine] != theme.Types[TypeMine] {
+		t.Error("override changed the theme itself")
+	}
+}
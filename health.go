@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// lastUpdate contains Unix time in nanoseconds of the last update received by any bot
+	lastUpdate atomic.Int64
+
+	// runningShards contains count of bots receiving updates
+	runningShards atomic.Int32
+)
+
+// markUpdate records that an update was received
+func markUpdate() {
+	lastUpdate.Store(time.Now().UnixNano())
+}
+
+// ready checks if bots are receiving updates, updates are expected to be
+// received within the timeout unless it's zero
+func ready(now time.Time, timeout time.Duration) bool {
+	if runningShards.Load() == 0 {
+		return false
+	}
+
+	return timeout <= 0 || now.Sub(time.Unix(0, lastUpdate.Load())) <= timeout
+}
+
+// serveHealth serves liveness probe on /healthz and readiness probe
+// on /readyz paths of the address
+func serveHealth(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready(time.Now(), time.Duration(botConfig.ReadyTimeout)*time.Second) {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte("ok\n"))
+	})
+
+	slog.Info("Serving health checks", "addr", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		slog.Error("Unable to serve health checks", "error", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	defer lastUpdate.Store(lastUpdate.Load())
+	defer runningShards.Store(runningShards.Load())
+
+	updatedAt := time.Now()
+	lastUpdate.Store(updatedAt.UnixNano())
+	tests := []struct {
+		name    string
+		shards  int32
+		elapsed time.Duration
+		timeout time.Duration
+		want    bool
+	}{
+		{"fresh", 1, 10 * time.Second, time.Minute, true},
+		{"stale", 1, 2 * time.Minute, time.Minute, false},
+		{"at the timeout", 1, time.Minute, time.Minute, true},
+		{"no timeout", 1, time.Hour, 0, true},
+		{"no running bots", 0, 0, time.Minute, false},
+	}
+
+	for _, test := range tests {
+		runningShards.Store(test.shards)
+		if got := ready(updatedAt.Add(test.elapsed), test.timeout); got != test.want {
+			t.Errorf("%s: ready() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	defaultLanguage = "en"
+)
+
+var (
+	translations = map[string]map[string]string{
+		"en": {
+			"help.title":        "Available commands:",
+			"help.help":         "Get this message",
+			"help.play":         "Play new game",
+			"help.play_preset":  "Play new game with preset difficulty",
+			"help.play_custom":  "Play new custom game",
+			"help.play_seed":    "Play new game with the shared mines layout",
+			"help.play_noguess": "Play new game solvable without guessing",
+			"help.play_manual":  "Play new game where empty cells don't open their neighbors",
+			"help.flag":         "Toggle flag mode",
+			"help.open":         "Open the cell, counting from 1",
+			"help.hint":         "Open one safe cell",
+			"help.duel":         "Reply to a message to play in turns with its author",
+			"help.daily":        "Play the daily challenge, same board for everyone",
+			"help.daily_top":    "Show fastest daily challenge wins",
+			"help.train":        "List practice patterns",
+			"help.demo":         "Watch the bot play a game by itself",
+			"help.train_name":   "Practice the pattern on a small board",
+			"help.undo":         "Undo the last move",
+			"help.analyze":      "Show the safest cell without opening it",
+			"help.watch":        "Get a link to watch current game",
+			"help.export":       "Export current or last finished board as text",
+			"help.replay":       "Replay your last finished game",
+			"help.replay_n":     "Replay your n-th last finished game",
+			"help.board":        "Re-send current board",
+			"help.cancel":       "Cancel current game",
+			"help.abandonall":   "Remove all your active games",
+			"help.restart":      "Play new game with the same settings as the last one",
+			"help.scoreboard":   "Show top players",
+			"help.stats":        "Show your stats",
+			"help.games":        "List active games of this chat",
+			"help.share":        "Get code of your finished game to share it",
+			"help.import":       "Replay game shared by its code",
+			"help.version":      "Show version of the bot",
+			"help.globalstats":  "Show win rates of popular boards",
+			"help.themes":       "Preview themes and pick one",
+			"help.assist":       "Toggle automatic flagging of obvious mines",
+			"help.verbose":      "Toggle counts of closed cells and numbers under the title",
+			"help.theme":        "Set theme for new games",
+			"help.setglyph":     "Replace mine or flag glyph in your boards",
+			"help.config":       "Show defaults of this chat",
+			"help.config_diff":  "Play this difficulty when /play has no arguments",
+			"help.config_theme": "Use this theme for players without their own one",
+			"help.config_lang":  "Use this language for boards in this chat",
+			"help.config_one":   "Allow only one active game in this chat",
+
+			"game.new":           "New game",
+			"game.title":         "Minesweeper",
+			"game.won":           "You won in %s!",
+			"game.lost":          "Game over in %s!",
+			"game.gave_up":       "You gave up after %s",
+			"game.timed_out":     "Game ended, no moves for too long",
+			"game.give_up":       "🏳️ Give up",
+			"game.cancelled":     "Game cancelled",
+			"game.moved":         "Board was moved below",
+			"game.not_found":     "There is no active game in this chat",
+			"game.expired":       "This game has expired, use /play to start a new one",
+			"game.confirm_start": "Tap the same cell again to start the game",
+			"game.not_yours":     "This isn't your game",
+			"game.already_won":   "This game is already won",
+			"game.already_lost":  "This game is already lost",
+			"game.play_again":    "Play again",
+			"game.mode_flag":     "Mode: %s flag",
+			"game.mode_open":     "Mode: %s open",
+
+			"summary.opened":    "Cells opened: %d/%d",
+			"summary.flags":     "Flags placed: %d, correct: %d",
+			"summary.remaining": "Safe cells left: %d",
+			"summary.player":    "%s: %d cells",
+
+			"duel.usage":         "Reply with /duel to a message of the player you want to play with",
+			"duel.turn":          "Turn: %s",
+			"duel.not_your_turn": "It's %s's turn",
+			"duel.won":           "%s opened the last cell in %s!",
+			"duel.lost":          "%s hit a mine in %s!",
+			"duel.gave_up":       "%s gave up after %s",
+			"duel.no_assist":     "Hints and undo are disabled in two-player games",
+
+			"restart.no_games": "There were no games in this chat yet, use /play to start one",
+			"restart.use_play": "Use /play to start a new game",
+
+			"start.welcome": "Welcome to Minesweeper! Open all cells without mines to win.",
+			"start.open":    "Tap a cell of the board to open it, numbers show how many mines are around. Tap an opened number to open its neighbors when all its mines are flagged.",
+			"start.flag":    "Use the mode button under the board or /flag to switch to flag mode and mark mines.",
+			"start.help":    "Send /play to pick board size or /help to see all commands.",
+			"start.play":    "Play now (%s)",
+
+			"hint.limit":    "You have already used %d of %d hints in this game",
+			"hint.no_cells": "There are no closed safe cells left",
+			"hint.used":     "Hint used: opened cell at row %d, column %d",
+
+			"analyze.safe":   "Cell at row %d, column %d is safe for sure",
+			"analyze.safest": "Safest cell is at row %d, column %d, mine probability is %d%%",
+			"analyze.mines":  "Mines known for sure but not flagged: %d",
+			"analyze.failed": "Unable to analyze this board",
+
+			"open.usage":  "Use /open <row> <column>, e.g. /open 3 5",
+			"open.bounds": "Row should be from 1 to %d and column from 1 to %d",
+
+			"toast.opened":    "Opened %d:%d",
+			"toast.chorded":   "Chorded %d:%d, opened %d",
+			"toast.flagged":   "Flagged %d:%d",
+			"toast.question":  "Marked %d:%d with question",
+			"toast.unflagged": "Unmarked %d:%d",
+			"toast.blocked":   "Cell %d:%d is flagged, unflag it to open",
+			"toast.nothing":   "Nothing to do here",
+
+			"replay.title":     "Replay, move %d of %d",
+			"replay.not_found": "No such finished game, only %d latest ones are kept",
+			"replay.number":    "Game number should be from 1 to %d",
+			"replay.empty":     "This game has no moves to replay",
+
+			"demo.title":   "Demo",
+			"demo.won":     "Demo won!",
+			"demo.lost":    "Demo hit a mine!",
+			"demo.unknown": "Unknown difficulty, available: %s",
+
+			"train.list":         "Practice patterns, play one with /train <pattern>:",
+			"train.unknown":      "Unknown pattern, available: %s",
+			"train.pattern_11":   "1-1 from a wall, the third cell is safe",
+			"train.pattern_121":  "1-2-1, mines are next to the ones",
+			"train.pattern_1221": "1-2-2-1, mines are next to the twos",
+			"train.pattern_5050": "unavoidable guess, recognize it and guess early",
+
+			"undo.empty": "There is no move to undo",
+
+			"scoreboard.title": "Scoreboard",
+			"scoreboard.empty": "Nobody has won yet",
+
+			"globalstats.title":    "Popular boards",
+			"globalstats.line":     "games: %d, win rate: %d%%",
+			"globalstats.empty":    "No games have been finished yet",
+			"globalstats.disabled": "Global stats are disabled by the bot owner",
+
+			"daily.title": "Daily challenge %s",
+			"daily.empty": "Nobody has solved today's challenge yet",
+
+			"stats.empty":      "You haven't finished any games yet",
+			"stats.title":      "Your stats",
+			"stats.games":      "Games: %d",
+			"stats.wins":       "Wins: %d",
+			"stats.losses":     "Losses: %d",
+			"stats.abandoned":  "Abandoned: %d",
+			"stats.win_rate":   "Win rate: %d%%",
+			"stats.best_times": "Best times",
+			"stats.streak":     "Win streak: %d, best: %d",
+
+			"streak.best":   "🔥 New best streak: %d!",
+			"streak.broken": "Streak of %d wins is broken",
+
+			"theme.current":  "Current theme is `%s`, available: %s",
+			"theme.unknown":  "Unknown theme, available: %s",
+			"theme.set":      "Theme `%s` will be used for your new games",
+			"theme.selected": "Theme %s will be used for your new games",
+			"theme.applied":  "Theme `%s` is applied to your current game and will be used for your new games",
+			"theme.preview":  "Tap a theme to use it for your new games:",
+
+			"glyph.usage":   "Usage: /setglyph mine|flag <emoji>, omit emoji to use the theme's one",
+			"glyph.invalid": "Glyph should be a single emoji or character",
+			"glyph.set":     "Your %s glyph is %s now",
+			"glyph.reset":   "Your %s glyph is reset to the theme's one",
+
+			"assist.enabled":  "Assist mode enabled, obvious mines will be flagged automatically",
+			"assist.disabled": "Assist mode disabled",
+
+			"verbose.enabled":  "Verbose mode enabled, boards show counts of closed cells and opened numbers",
+			"verbose.disabled": "Verbose mode disabled",
+			"verbose.line":     "Closed: %d, numbers: %s",
+
+			"config.current":   "Chat settings:\nDifficulty: `%s`\nTheme: `%s`\nLanguage: `%s`\nSingle game: `%s`",
+			"config.usage":     "Use /config difficulty|theme|lang|single <value>",
+			"config.invalid":   "Unknown %s, available: %s",
+			"config.set":       "Chat %s is set to `%s`",
+			"config.unset":     "not set",
+			"config.not_admin": "Only chat administrators can change chat settings",
+
+			"watch.link":      "Share this link to let others watch the game: %s",
+			"watch.limit":     "This game already has too many watchers",
+			"watch.read_only": "This is a read-only copy of the game",
+
+			"admin.usage":          "Usage: /admin broadcast <text>",
+			"admin.broadcast_sent": "Broadcast sent to %d of %d chats",
+
+			"leaderboard.usage":          "Usage: /leaderboard or /leaderboard reset",
+			"leaderboard.not_admin":      "Only admins can reset the leaderboard",
+			"leaderboard.archive_failed": "Unable to archive the leaderboard, it's left untouched",
+			"leaderboard.reset":          "The leaderboard is reset, new season begins! Results of %d players are archived",
+
+			"delay.usage":     "Usage: /setdelay <ms>, current poll delay is %d ms, maximum is %d ms",
+			"delay.not_admin": "Only admins can change the poll delay",
+			"delay.set":       "Poll delay is set to %d ms",
+
+			"share.code":        "Send this to a friend to let them watch your game:\n`/import %s`",
+			"share.unavailable": "This game can't be shared",
+			"import.usage":      "Usage: /import <code>",
+			"import.invalid":    "The code is damaged or made by a different version of the bot",
+
+			"games.title": "Active games: %d",
+			"games.empty": "There are no active games in this chat",
+			"games.open":  "open",
+
+			"version.build":  "Version %s, commit %s, built %s",
+			"version.status": "Mode: %s, active games: %d",
+
+			"abandon.deleted":   "Active games removed: %d",
+			"abandon.not_admin": "Only admins can remove games of other users",
+			"abandon.user_id":   "User ID should be a number",
+
+			"prompt.width":  "Enter minefield width:",
+			"prompt.height": "Enter minefield height:",
+			"prompt.mines":  "Enter mines count:",
+			"prompt.retry":  "%s. Try again, attempts left: %d",
+
+			"error.cancelled":     "Game creation cancelled",
+			"error.max_games":     "You already have %d games running, finish one of them first",
+			"error.rate_limited":  "Slow down, you're tapping too fast",
+			"error.send_board":    "Sorry, the board couldn't be sent, please try again",
+			"error.seed":          "Seed should be a number",
+			"error.width":         "Width",
+			"error.height":        "Height",
+			"error.size_number":   "%s should be a number",
+			"error.size_range":    "%s should be in between `%d` and `%d`",
+			"error.internal":      "Something went wrong, please try again",
+			"error.chat_busy":     "Only one game at a time is allowed in this chat, finish this one first",
+			"error.keyboard_size": "Minefield is too large to be shown by Telegram, try smaller one",
+			"error.mines_number":  "Mines count should be a number",
+			"error.mines_min":     "Mines count should be at least `%d`",
+			"error.mines_max":     "Max mines count for `%d` by `%d` minefield is `%d`, you entered `%d`",
+		},
+		"ru": {
+			"help.title":        "Доступные команды:",
+			"help.help":         "Показать это сообщение",
+			"help.play":         "Начать новую игру",
+			"help.play_preset":  "Начать новую игру заданной сложности",
+			"help.play_custom":  "Начать новую игру со своими параметрами",
+			"help.play_seed":    "Начать новую игру с общей расстановкой мин",
+			"help.play_noguess": "Начать новую игру, которую можно решить без угадывания",
+			"help.play_manual":  "Начать новую игру, где пустые клетки не открывают соседей",
+			"help.flag":         "Переключить режим флажков",
+			"help.open":         "Открыть клетку, счёт с 1",
+			"help.hint":         "Открыть одну безопасную клетку",
+			"help.duel":         "Ответьте на сообщение, чтобы играть по очереди с его автором",
+			"help.daily":        "Сыграть ежедневное испытание, одно поле для всех",
+			"help.daily_top":    "Показать самые быстрые победы в ежедневном испытании",
+			"help.train":        "Показать тренировочные шаблоны",
+			"help.demo":         "Посмотреть, как бот играет сам",
+			"help.train_name":   "Потренировать шаблон на маленьком поле",
+			"help.undo":         "Отменить последний ход",
+			"help.analyze":      "Показать самую безопасную клетку, не открывая её",
+			"help.watch":        "Получить ссылку для наблюдения за текущей игрой",
+			"help.export":       "Экспортировать текущее или последнее законченное поле в виде текста",
+			"help.replay":       "Повторить вашу последнюю законченную игру",
+			"help.replay_n":     "Повторить вашу n-ю с конца законченную игру",
+			"help.board":        "Отправить текущее поле заново",
+			"help.cancel":       "Отменить текущую игру",
+			"help.abandonall":   "Удалить все ваши активные игры",
+			"help.restart":      "Начать новую игру с параметрами предыдущей",
+			"help.scoreboard":   "Показать лучших игроков",
+			"help.stats":        "Показать вашу статистику",
+			"help.games":        "Показать активные игры этого чата",
+			"help.share":        "Получить код законченной игры, чтобы поделиться ею",
+			"help.import":       "Воспроизвести игру по её коду",
+			"help.version":      "Показать версию бота",
+			"help.globalstats":  "Показать процент побед на популярных полях",
+			"help.themes":       "Посмотреть темы и выбрать одну",
+			"help.assist":       "Переключить автоматическую отметку очевидных мин",
+			"help.verbose":      "Переключить показ числа закрытых клеток и цифр под заголовком",
+			"help.theme":        "Выбрать тему для новых игр",
+			"help.setglyph":     "Заменить значок мины или флага на ваших полях",
+			"help.config":       "Показать настройки этого чата",
+			"help.config_diff":  "Сложность для /play без аргументов",
+			"help.config_theme": "Тема для игроков, не выбравших свою",
+			"help.config_lang":  "Язык полей в этом чате",
+			"help.config_one":   "Разрешить только одну активную игру в этом чате",
+
+			"game.new":           "Новая игра",
+			"game.title":         "Сапёр",
+			"game.won":           "Вы победили за %s!",
+			"game.lost":          "Игра окончена за %s!",
+			"game.gave_up":       "Вы сдались через %s",
+			"game.timed_out":     "Игра завершена, слишком долго не было ходов",
+			"game.give_up":       "🏳️ Сдаться",
+			"game.cancelled":     "Игра отменена",
+			"game.moved":         "Поле перенесено ниже",
+			"game.not_found":     "В этом чате нет активной игры",
+			"game.expired":       "Эта игра устарела, начните новую с помощью /play",
+			"game.confirm_start": "Нажмите на ту же клетку ещё раз, чтобы начать игру",
+			"game.not_yours":     "Это не ваша игра",
+			"game.already_won":   "Эта игра уже выиграна",
+			"game.already_lost":  "Эта игра уже проиграна",
+			"game.play_again":    "Играть снова",
+			"game.mode_flag":     "Режим: %s флажок",
+			"game.mode_open":     "Режим: %s открыть",
+
+			"summary.opened":    "Открыто клеток: %d/%d",
+			"summary.flags":     "Поставлено флажков: %d, верных: %d",
+			"summary.remaining": "Осталось безопасных клеток: %d",
+			"summary.player":    "%s: %d клеток",
+
+			"duel.usage":         "Ответьте командой /duel на сообщение игрока, с которым хотите сыграть",
+			"duel.turn":          "Ход: %s",
+			"duel.not_your_turn": "Сейчас ходит %s",
+			"duel.won":           "%s: последняя клетка открыта за %s!",
+			"duel.lost":          "%s: взрыв на мине за %s!",
+			"duel.gave_up":       "%s: сдача через %s",
+			"duel.no_assist":     "Подсказки и отмена ходов недоступны в игре вдвоём",
+
+			"restart.no_games": "В этом чате ещё не было игр, используйте /play чтобы начать",
+			"restart.use_play": "Используйте /play чтобы начать новую игру",
+
+			"start.welcome": "Добро пожаловать в Сапёр! Откройте все клетки без мин, чтобы победить.",
+			"start.open":    "Нажмите на клетку поля, чтобы открыть её, числа показывают количество мин вокруг. Нажмите на открытое число, чтобы открыть соседей, когда все его мины отмечены.",
+			"start.flag":    "Кнопка режима под полем или /flag переключает режим флажков для отметки мин.",
+			"start.help":    "Отправьте /play, чтобы выбрать размер поля, или /help, чтобы увидеть все команды.",
+			"start.play":    "Играть сейчас (%s)",
+
+			"hint.limit":    "Вы уже использовали %d из %d подсказок в этой игре",
+			"hint.no_cells": "Не осталось закрытых безопасных клеток",
+			"hint.used":     "Подсказка использована: открыта клетка в строке %d, столбце %d",
+
+			"analyze.safe":   "Клетка в строке %d, столбце %d точно безопасна",
+			"analyze.safest": "Самая безопасная клетка в строке %d, столбце %d, вероятность мины %d%%",
+			"analyze.mines":  "Точно известных, но не отмеченных мин: %d",
+			"analyze.failed": "Не удалось проанализировать это поле",
+
+			"open.usage":  "Используйте /open <строка> <столбец>, например /open 3 5",
+			"open.bounds": "Строка должна быть от 1 до %d, а столбец от 1 до %d",
+
+			"toast.opened":    "Открыта %d:%d",
+			"toast.chorded":   "Открыто вокруг %d:%d: %d",
+			"toast.flagged":   "Флажок на %d:%d",
+			"toast.question":  "Вопрос на %d:%d",
+			"toast.unflagged": "Отметка снята с %d:%d",
+			"toast.blocked":   "На клетке %d:%d флажок, снимите его, чтобы открыть",
+			"toast.nothing":   "Здесь нечего делать",
+
+			"replay.title":     "Повтор, ход %d из %d",
+			"replay.not_found": "Нет такой законченной игры, хранятся только %d последних",
+			"replay.number":    "Номер игры должен быть от 1 до %d",
+			"replay.empty":     "В этой игре нет ходов для повтора",
+
+			"demo.title":   "Демо",
+			"demo.won":     "Демо выиграно!",
+			"demo.lost":    "Демо подорвалось на мине!",
+			"demo.unknown": "Неизвестная сложность, доступные: %s",
+
+			"train.list":         "Тренировочные шаблоны, сыграйте с помощью /train <шаблон>:",
+			"train.unknown":      "Неизвестный шаблон, доступные: %s",
+			"train.pattern_11":   "1-1 у стены, третья клетка безопасна",
+			"train.pattern_121":  "1-2-1, мины рядом с единицами",
+			"train.pattern_1221": "1-2-2-1, мины рядом с двойками",
+			"train.pattern_5050": "неизбежное угадывание, распознайте его и угадывайте сразу",
+
+			"undo.empty": "Нет хода, который можно отменить",
+
+			"scoreboard.title": "Таблица лидеров",
+			"scoreboard.empty": "Пока никто не победил",
+
+			"globalstats.title":    "Популярные поля",
+			"globalstats.line":     "игр: %d, процент побед: %d%%",
+			"globalstats.empty":    "Ещё нет законченных игр",
+			"globalstats.disabled": "Общая статистика отключена владельцем бота",
+
+			"daily.title": "Ежедневное испытание %s",
+			"daily.empty": "Сегодняшнее испытание ещё никто не прошёл",
+
+			"stats.empty":      "Вы ещё не завершили ни одной игры",
+			"stats.title":      "Ваша статистика",
+			"stats.games":      "Игр: %d",
+			"stats.wins":       "Побед: %d",
+			"stats.losses":     "Поражений: %d",
+			"stats.abandoned":  "Брошено: %d",
+			"stats.win_rate":   "Процент побед: %d%%",
+			"stats.best_times": "Лучшее время",
+			"stats.streak":     "Серия побед: %d, лучшая: %d",
+
+			"streak.best":   "🔥 Новая лучшая серия: %d!",
+			"streak.broken": "Серия из %d побед прервана",
+
+			"theme.current":  "Текущая тема `%s`, доступные: %s",
+			"theme.unknown":  "Неизвестная тема, доступные: %s",
+			"theme.set":      "Тема `%s` будет использоваться в ваших новых играх",
+			"theme.selected": "Тема %s будет использоваться в ваших новых играх",
+			"theme.applied":  "Тема `%s` применена к вашей текущей игре и будет использоваться в новых играх",
+			"theme.preview":  "Нажмите на тему, чтобы использовать её в новых играх:",
+
+			"glyph.usage":   "Использование: /setglyph mine|flag <эмодзи>, без эмодзи вернётся значок темы",
+			"glyph.invalid": "Значок должен быть одним эмодзи или символом",
+			"glyph.set":     "Ваш значок %s теперь %s",
+			"glyph.reset":   "Ваш значок %s сброшен на значок темы",
+
+			"assist.enabled":  "Режим помощи включён, очевидные мины будут отмечаться автоматически",
+			"assist.disabled": "Режим помощи выключен",
+
+			"verbose.enabled":  "Подробный режим включён, на полях будет видно число закрытых клеток и открытых цифр",
+			"verbose.disabled": "Подробный режим выключен",
+			"verbose.line":     "Закрыто: %d, цифры: %s",
+
+			"config.current":   "Настройки чата:\nСложность: `%s`\nТема: `%s`\nЯзык: `%s`\nОдна игра: `%s`",
+			"config.usage":     "Используйте /config difficulty|theme|lang|single <значение>",
+			"config.invalid":   "Неизвестное значение %s, доступные: %s",
+			"config.set":       "Параметр чата %s установлен в `%s`",
+			"config.unset":     "не задано",
+			"config.not_admin": "Только администраторы чата могут менять настройки чата",
+
+			"watch.link":      "Поделитесь ссылкой, чтобы другие могли наблюдать за игрой: %s",
+			"watch.limit":     "У этой игры уже слишком много наблюдателей",
+			"watch.read_only": "Это копия игры только для просмотра",
+
+			"admin.usage":          "Использование: /admin broadcast <текст>",
+			"admin.broadcast_sent": "Рассылка отправлена в %d из %d чатов",
+
+			"leaderboard.usage":          "Использование: /leaderboard или /leaderboard reset",
+			"leaderboard.not_admin":      "Только администраторы могут сбросить таблицу лидеров",
+			"leaderboard.archive_failed": "Не удалось сохранить таблицу лидеров в архив, она не изменена",
+			"leaderboard.reset":          "Таблица лидеров сброшена, начинается новый сезон! Результаты %d игроков сохранены в архив",
+
+			"delay.usage":     "Использование: /setdelay <мс>, текущая задержка опроса %d мс, максимум %d мс",
+			"delay.not_admin": "Только администраторы могут менять задержку опроса",
+			"delay.set":       "Задержка опроса установлена в %d мс",
+
+			"share.code":        "Отправьте это другу, чтобы он посмотрел вашу игру:\n`/import %s`",
+			"share.unavailable": "Этой игрой нельзя поделиться",
+			"import.usage":      "Использование: /import <код>",
+			"import.invalid":    "Код повреждён или создан другой версией бота",
+
+			"games.title": "Активных игр: %d",
+			"games.empty": "В этом чате нет активных игр",
+			"games.open":  "открыть",
+
+			"version.build":  "Версия %s, коммит %s, собрана %s",
+			"version.status": "Режим: %s, активных игр: %d",
+
+			"abandon.deleted":   "Удалено активных игр: %d",
+			"abandon.not_admin": "Только администраторы могут удалять игры других пользователей",
+			"abandon.user_id":   "ID пользователя должен быть числом",
+
+			"prompt.width":  "Введите ширину поля:",
+			"prompt.height": "Введите высоту поля:",
+			"prompt.mines":  "Введите количество мин:",
+			"prompt.retry":  "%s. Попробуйте ещё раз, осталось попыток: %d",
+
+			"error.cancelled":     "Создание игры отменено",
+			"error.max_games":     "У вас уже запущено игр: %d, сначала закончите одну из них",
+			"error.rate_limited":  "Помедленнее, вы нажимаете слишком быстро",
+			"error.send_board":    "Не удалось отправить поле, попробуйте ещё раз",
+			"error.seed":          "Сид должен быть числом",
+			"error.width":         "Ширина",
+			"error.height":        "Высота",
+			"error.size_number":   "%s должна быть числом",
+			"error.size_range":    "%s должна быть от `%d` до `%d`",
+			"error.internal":      "Что-то пошло не так, попробуйте ещё раз",
+			"error.chat_busy":     "В этом чате можно играть только одну игру одновременно, сначала закончите эту",
+			"error.keyboard_size": "Поле слишком большое для отображения в Telegram, попробуйте поменьше",
+			"error.mines_number":  "Количество мин должно быть числом",
+			"error.mines_min":     "Количество мин должно быть не меньше `%d`",
+			"error.mines_max":     "Максимальное количество мин для поля `%d` на `%d` — `%d`, вы ввели `%d`",
+		},
+	}
+)
+
+// tr returns message translated to the language and formatted with args,
+// english message is used when translation is missing
+func tr(lang, key string, args ...interface{}) string {
+	message, ok := translations[lang][key]
+	if !ok {
+		message, ok = translations[defaultLanguage][key]
+	}
+
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+
+	return fmt.Sprintf(message, args...)
+}
+
+func userLanguage(user *tgbot.User) string {
+	if user == nil {
+		return defaultLanguage
+	}
+
+	lang := strings.ToLower(strings.SplitN(user.LanguageCode, "-", 2)[0])
+	if _, ok := translations[lang]; ok {
+		return lang
+	}
+
+	return defaultLanguage
+}
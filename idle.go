@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+)
+
+// endIdleGames periodically ends games without moves for longer than the timeout,
+// their boards reveal mines and they're counted as abandoned by their owners
+func endIdleGames(timeout time.Duration) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, game := range games.Idle(time.Now().Add(-timeout)) {
+			endIdleGame(game, timeout)
+		}
+	}
+}
+
+func endIdleGame(game *Game, timeout time.Duration) {
+	bot, ok := shardBot(game.Shard)
+	if !ok {
+		slog.Debug("Bot of idle game is unknown yet", "game", game.Key())
+		return
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	// the game could be played, cancelled or replaced by a new game on the
+	// same board while it waited for the lock
+	if current, ok := games.Get(game.Key()); !ok || current != game {
+		return
+	}
+
+	if time.Since(game.LastActivity()) < timeout || !game.Minefield.GiveUp() {
+		return
+	}
+
+	scoreboard.AddAbandoned(game.OwnerID)
+	games.Finish(game)
+	slog.Info("Idle game ended", "game", game.Key(), "owner_id", game.OwnerID)
+
+	text := renderText(game, tr(game.Language, "game.timed_out")) + "\n\n" + renderSummary(game)
+	config := game.EditConfig()
+	config.Text = text
+	config.ReplyMarkup = renderMinefield(game)
+	if err := editMessage(bot, config); err != nil {
+		slog.Error("Unable to update board", "game", game.Key(), "error", err)
+		return
+	}
+
+	game.signature = boardSignature(config.Text, config.ReplyMarkup)
+	updateMirrors(bot, game, text)
+	updateTextBoard(bot, game, text)
+}
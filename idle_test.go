@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/floodcode/tgbot"
+)
+
+func TestEndIdleGame(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	defer func(previous *Scoreboard) { scoreboard = previous }(scoreboard)
+	games = newGameStore()
+	scoreboard = newScoreboard()
+
+	bot := &tgbot.TelegramBot{}
+	bindShard(bot, "idle")
+	t.Cleanup(func() {
+		shardsMu.Lock()
+		delete(shards, bot)
+		shardsMu.Unlock()
+	})
+
+	newIdleGame := func(messageID int) *Game {
+		game := testGame(1, 10, messageID)
+		game.Shard = "idle"
+		game.CreatedAt = time.Now().Add(-2 * time.Hour)
+		return game
+	}
+
+	edits := withSendEdit(t)
+	game := newIdleGame(100)
+	games.Set(game)
+	endIdleGame(game, time.Hour)
+
+	if game.Minefield.State != GameLose {
+		t.Errorf("state of idle game = %d, want lose", game.Minefield.State)
+	}
+
+	if score, _ := scoreboard.Get(1); score.Abandoned != 1 {
+		t.Errorf("abandoned games = %d, want 1", score.Abandoned)
+	}
+
+	if _, ok := games.Get(game.Key()); ok {
+		t.Error("idle game is still active")
+	}
+
+	if *edits != 1 {
+		t.Errorf("board edited %d times, want 1", *edits)
+	}
+
+	// idle game found before it was replaced by a new game on the same board
+	stale := newIdleGame(101)
+	games.Set(newIdleGame(101))
+	endIdleGame(stale, time.Hour)
+
+	if stale.Minefield.State != GameRunning {
+		t.Errorf("state of replaced game = %d, want running", stale.Minefield.State)
+	}
+
+	if score, _ := scoreboard.Get(1); score.Abandoned != 1 {
+		t.Errorf("abandoned games after replaced game = %d, want 1", score.Abandoned)
+	}
+
+	if *edits != 1 {
+		t.Errorf("board edited %d times, want 1", *edits)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+// Inline mode requires inline queries to be enabled with /setinline command
+// of @BotFather and inline feedback to be enabled with /setinlinefeedback,
+// otherwise chosen results are not delivered and boards can't be registered.
+
+func inlineQueryListener(req tbf.InlineQueryRequest) {
+	lang := userLanguage(req.InlineQuery.From)
+	seed := newSeed()
+
+	results := make([]tgbot.InlineQueryResultArticle, 0, len(difficulties))
+	for _, name := range difficultyNames() {
+		difficulty := difficulties[name]
+		game := &Game{
+			Minefield: newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, seed),
+			Theme:     settings.Get(req.InlineQuery.From.ID).Theme,
+			Language:  lang,
+		}
+
+		results = append(results, tgbot.InlineQueryResultArticle{
+			ID:          fmt.Sprintf("%s:%d", name, seed),
+			Title:       name,
+			Description: difficulty.String(),
+			InputMessageContent: tgbot.InputTextMessageContent{
+				MessageText: renderText(game, tr(lang, "game.new")),
+			},
+			ReplyMarkup: renderMinefield(game),
+		})
+	}
+
+	err := req.Answer(tgbot.AnswerInlineQueryConfig{
+		InlineQueryID: req.InlineQuery.ID,
+		Results:       results,
+		IsPersonal:    true,
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to answer inline query", "error", err)
+	}
+}
+
+func chosenInlineResultListener(req tbf.ChosenInlineResultRequest) {
+	result := req.ChosenInlineResult
+	parts := strings.SplitN(result.ResultID, ":", 2)
+	if len(parts) != 2 || len(result.InlineMessageID) == 0 {
+		slog.Warn("Invalid chosen inline result", "result_id", result.ResultID)
+		return
+	}
+
+	difficulty, ok := difficulties[parts[0]]
+	seed, err := strconv.ParseInt(parts[1], 10, 64)
+	if !ok || err != nil {
+		slog.Warn("Invalid chosen inline result", "result_id", result.ResultID)
+		return
+	}
+
+	game := newGame(result.From, newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, seed))
+	game.InlineMessageID = result.InlineMessageID
+
+	games.Set(game)
+	gamesCreated.Inc()
+	slog.Info("Inline game created",
+		"game", game.Key(),
+		"owner_id", result.From.ID,
+		"width", difficulty.Width,
+		"height", difficulty.Height,
+		"mines", difficulty.Mines,
+		"seed", seed,
+	)
+}
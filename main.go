@@ -1,225 +1,1699 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/floodcode/gosweep"
 	"github.com/floodcode/tbf"
 	"github.com/floodcode/tgbot"
 )
 
 const (
-	configPath     = "config.json"
 	playGameRegexp = `([0-9]+)\s+([0-9]+)\s+([0-9]+)`
 	minMines       = 1
 	minSize        = 4
-	maxSize        = 8
+	maxSize        = 16
+	maxHints       = 1
+	seedPrefix     = "seed:"
+	noGuessArg     = "noguess"
+	manualArg      = "manual"
+
+	maxPromptAttempts = 3
+)
+
+// maxKeyboardWidth is the most buttons Telegram shows in a keyboard row
+const maxKeyboardWidth = 8
+
+var (
+	suggestedDensities = []float64{0.1, 0.15, 0.2, 0.25}
+
+	errGameCancelled = errors.New("Game creation cancelled")
+	errHintLimit     = errors.New("Hints limit reached")
+	errNoSafeCell    = errors.New("No safe cells left")
+
+	playGameRe = regexp.MustCompile(playGameRegexp)
+
+	difficulties = map[string]Difficulty{
+		"easy":   {Width: 4, Height: 4, Mines: 3},
+		"medium": {Width: 6, Height: 6, Mines: 8},
+		"hard":   {Width: 8, Height: 8, Mines: 15},
+	}
+)
+
+const (
+	actionToggleMode = "mode"
+	actionRestart    = "restart"
+	actionNoop       = "noop"
+	actionTheme      = "theme"
+	actionPlay       = "play"
+	actionWatch      = "watch"
+	actionGiveUp     = "giveup"
+	actionScroll     = "scroll"
 )
 
 var (
-	games = map[int]*gosweep.Minefield{}
+	botConfig  BotConfig
+	games      = newGameStore()
+	scoreboard = newScoreboard()
+	settings   = newSettingsStore()
+	limiter    *RateLimiter
+
+	// demoLimiter limits demo moves separately, so a running demo doesn't
+	// use up the rate limit of the user's own games
+	demoLimiter *RateLimiter
+
+	chatSettings = newChatSettingsStore()
+	replays      = newReplayStore()
+	outcomes     = newOutcomeStats()
+
+	dailyResults = newDailyResults()
+
+	shutdownOnce sync.Once
 )
 
-// BotConfig contains bot's environment variables
-type BotConfig struct {
-	Token string `json:"token"`
-	Delay int    `json:"delay"`
+// Difficulty contains minefield parameters of a game preset
+type Difficulty struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Mines  int `json:"mines"`
+}
+
+// String returns short description like 8x8/10
+func (d Difficulty) String() string {
+	return fmt.Sprintf("%dx%d/%d", d.Width, d.Height, d.Mines)
 }
 
 // CellCallbackData used to store callback data for each minefield cell
 type CellCallbackData struct {
-	Row int `json:"row"`
-	Col int `json:"col"`
+	Game   string `json:"game,omitempty"`
+	Action string `json:"action,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Row    int    `json:"row"`
+	Col    int    `json:"col"`
 }
 
 func main() {
-	configData, err := ioutil.ReadFile(configPath)
-	checkError(err)
+	var err error
+	botConfig, err = loadConfig(configPath)
+	if err != nil {
+		slog.Error("Unable to load config", "error", err)
+		os.Exit(1)
+	}
 
-	var config BotConfig
-	err = json.Unmarshal(configData, &config)
+	err = setupLogger(botConfig.LogLevel)
 	checkError(err)
 
-	bot, err := tbf.New(config.Token)
+	pollDelay.Store(int64(botConfig.Delay))
+
+	if botConfig.RateLimit > 0 {
+		limiter = newRateLimiter(botConfig.RateLimit, botConfig.RateBurst)
+		demoLimiter = newRateLimiter(botConfig.RateLimit, botConfig.RateBurst)
+		go evictBuckets(limiter)
+		go evictBuckets(demoLimiter)
+	}
+
+	if len(botConfig.StatePath) > 0 {
+		err = loadState(botConfig.StatePath)
+		checkError(err)
+
+		go saveStatePeriodically(botConfig.StatePath, time.Duration(botConfig.SaveInterval)*time.Second)
+	}
+
+	if len(botConfig.MetricsAddr) > 0 {
+		go serveMetrics(botConfig.MetricsAddr)
+	}
+
+	if len(botConfig.HealthAddr) > 0 {
+		go serveHealth(botConfig.HealthAddr)
+	}
+
+	if botConfig.GameTTL > 0 {
+		go evictGames(time.Duration(botConfig.GameTTL) * time.Minute)
+	}
+
+	if botConfig.IdleTimeout > 0 {
+		go endIdleGames(time.Duration(botConfig.IdleTimeout) * time.Minute)
+	}
+
+	tokens := botConfig.AllTokens()
+	errs := make(chan error, len(tokens))
+	for _, token := range tokens {
+		var id string
+		if len(tokens) > 1 {
+			id = shardToken(token)
+		}
+
+		bot, err := newShard(token, id)
+		checkError(err)
+
+		go func() {
+			errs <- runShard(bot)
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	for running := len(tokens); running > 0; {
+		select {
+		case err = <-errs:
+			running--
+			if running > 0 {
+				slog.Error("Bot stopped, other bots keep running", "error", err)
+			}
+		case <-signals:
+			// graceful stop isn't an error even when some bots stopped before it
+			running, err = 0, nil
+		}
+	}
+
+	shutdown()
 	checkError(err)
+}
 
-	bot.AddRoute("start", helpAction)
-	bot.AddRoute("help", helpAction)
-	bot.AddRoute("play", playAction)
-	bot.OnCallbackQuery(callbackQueryListener)
+// shutdown flushes in-memory state, it's safe to call it multiple times
+func shutdown() {
+	shutdownOnce.Do(func() {
+		if len(botConfig.StatePath) == 0 {
+			return
+		}
 
-	err = bot.Poll(tbf.PollConfig{
-		Delay: config.Delay,
+		if err := saveState(botConfig.StatePath); err != nil {
+			slog.Error("Unable to save state", "error", err)
+		}
 	})
+}
 
-	checkError(err)
+func run(bot *tbf.TelegramBotFramework) error {
+	switch botConfig.Mode {
+	case "", modePoll:
+		maxBackoff := time.Duration(botConfig.PollMaxBackoff) * time.Second
+		return pollWithBackoff(newPoller(bot).poll, maxBackoff)
+	case modeWebhook:
+		return bot.Listen(tbf.ListenConfig{
+			Addr:         botConfig.Webhook.ListenAddr,
+			URL:          botConfig.Webhook.URL,
+			CertFilename: botConfig.Webhook.CertPath,
+			KeyFilename:  botConfig.Webhook.KeyPath,
+		})
+	default:
+		return fmt.Errorf("Unknown mode %q, expected %q or %q", botConfig.Mode, modePoll, modeWebhook)
+	}
 }
 
-func helpAction(req tbf.Request) {
-	req.QuickMessageMD(fmt.Sprintf(strings.Join([]string{
-		"Available commads:",
-		"/help - Get this message",
-		"/play - Play new game",
-	}, "\n")))
+// pollWithBackoff restarts polling stopped by an error, delay between attempts
+// doubles up to maxBackoff and is reset when polling worked for longer than it
+func pollWithBackoff(poll func() error, maxBackoff time.Duration) error {
+	if maxBackoff <= 0 {
+		maxBackoff = defaultPollBackoff
+	}
+
+	backoff := minPollBackoff
+	for {
+		started := time.Now()
+		err := poll()
+		if err == nil {
+			return nil
+		}
+
+		if time.Since(started) > maxBackoff {
+			backoff = minPollBackoff
+		}
+
+		slog.Error("Polling stopped, reconnecting", "error", err, "backoff", backoff)
+		retrySleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
 }
 
 func playAction(req tbf.Request) {
-	game, err := createGame(req)
-	if err != nil {
-		req.QuickMessageMD(err.Error())
+	if gameLimitReached(req) {
+		return
+	}
+
+	lang := userLanguage(req.Message.From)
+	minefield, err := createGame(req)
+	if err == errGameCancelled {
+		req.QuickMessage(tr(lang, "error.cancelled"))
+		return
+	} else if err != nil {
+		req.QuickMessageMD(errorText(lang, err))
 		return
 	}
 
-	msg, err := req.SendMessage(tgbot.SendMessageConfig{
-		Text:        "New game",
+	startGame(req.Bot, req.Message.Chat.ID, newGame(req.Message.From, minefield))
+}
+
+func duelAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	reply := req.Message.ReplyToMessage
+	if reply == nil || reply.From == nil || reply.From.IsBot || reply.From.ID == req.Message.From.ID {
+		req.QuickMessage(tr(lang, "duel.usage"))
+		return
+	}
+
+	if gameLimitReached(req) {
+		return
+	}
+
+	minefield, err := createGame(req)
+	if err == errGameCancelled {
+		req.QuickMessage(tr(lang, "error.cancelled"))
+		return
+	} else if err != nil {
+		req.QuickMessageMD(errorText(lang, err))
+		return
+	}
+
+	game := newGame(req.Message.From, minefield)
+	game.Players = []Player{
+		{ID: req.Message.From.ID, Name: userName(req.Message.From)},
+		{ID: reply.From.ID, Name: userName(reply.From)},
+	}
+
+	startGame(req.Bot, req.Message.Chat.ID, game)
+}
+
+func restartAction(req tbf.Request) {
+	difficulty, ok := games.GetLast(shardID(req.Bot), req.Message.Chat.ID)
+	if !ok {
+		req.QuickMessage(tr(userLanguage(req.Message.From), "restart.no_games"))
+		return
+	}
+
+	if gameLimitReached(req) {
+		return
+	}
+
+	minefield := newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, newSeed())
+	startGame(req.Bot, req.Message.Chat.ID, newGame(req.Message.From, minefield))
+}
+
+// commandGame returns game whose board the command replies to,
+// latest game of the chat is used when command isn't a reply
+func commandGame(req tbf.Request) (*Game, bool) {
+	shard := shardID(req.Bot)
+	if reply := req.Message.ReplyToMessage; reply != nil {
+		if game, ok := games.Get(shardKey(shard, messageKey(reply.Chat.ID, reply.MessageID))); ok {
+			return game, true
+		}
+	}
+
+	return games.GetByChat(shard, req.Message.Chat.ID)
+}
+
+// playableGame returns game the command applies to if the sender may change it,
+// otherwise the sender is told why the command is ignored
+func playableGame(req tbf.Request) (*Game, bool) {
+	lang := userLanguage(req.Message.From)
+	game, ok := commandGame(req)
+	if !ok {
+		req.QuickMessage(tr(lang, "game.not_found"))
+		return nil, false
+	}
+
+	if !game.Playable(req.Message.From.ID) {
+		req.QuickMessage(tr(lang, "game.not_yours"))
+		return nil, false
+	}
+
+	return game, true
+}
+
+// gamesAction lists active games of the chat with links to their boards
+func gamesAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	chatGames := games.ByChat(shardID(req.Bot), req.Message.Chat.ID)
+	if len(chatGames) == 0 {
+		req.QuickMessage(tr(lang, "games.empty"))
+		return
+	}
+
+	lines := []string{tr(lang, "games.title", len(chatGames))}
+	for i, game := range chatGames {
+		line := fmt.Sprintf("%d. `%s` — %s", i+1, game.Minefield.Difficulty(), time.Since(game.CreatedAt).Round(time.Second))
+		if link, ok := messageLink(game.ChatID, game.MessageID); ok {
+			line += " — [" + tr(lang, "games.open") + "](" + link + ")"
+		}
+
+		lines = append(lines, line)
+	}
+
+	req.QuickMessageMD(strings.Join(lines, "\n"))
+}
+
+// messageLink returns link to the message, only messages of supergroups
+// and channels can be linked by their IDs
+func messageLink(chatID, messageID int) (string, bool) {
+	const channelPrefix = "-100"
+	id := strconv.Itoa(chatID)
+	if !strings.HasPrefix(id, channelPrefix) {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://t.me/c/%s/%d", id[len(channelPrefix):], messageID), true
+}
+
+// gameLimitReached checks if the sender already runs the maximum allowed
+// count of games and tells them about it
+func gameLimitReached(req tbf.Request) bool {
+	if game, busy := chatBusyGame(shardID(req.Bot), req.Message.Chat.ID); busy {
+		_, err := req.Bot.SendMessage(tgbot.SendMessageConfig{
+			ChatID:           tgbot.ChatID(req.Message.Chat.ID),
+			Text:             tr(userLanguage(req.Message.From), "error.chat_busy"),
+			ReplyToMessageID: game.MessageID,
+		})
+
+		if err != nil {
+			apiErrors.Inc()
+			slog.Error("Unable to point to active game", "game", game.Key(), "error", err)
+		}
+
+		return true
+	}
+
+	text, reached := gameLimitText(req.Message.From)
+	if reached {
+		req.QuickMessage(text)
+	}
+
+	return reached
+}
+
+// chatBusyGame returns active game of the chat if only one game at a time
+// is allowed there by single_game config field or chat settings
+func chatBusyGame(shard string, chatID int) (*Game, bool) {
+	if !botConfig.SingleGame && !chatSettings.Get(shard, chatID).SingleGame {
+		return nil, false
+	}
+
+	return games.GetByChat(shard, chatID)
+}
+
+// gameLimitText returns message for users running the maximum allowed count of games
+func gameLimitText(user *tgbot.User) (string, bool) {
+	if botConfig.MaxGames <= 0 {
+		return "", false
+	}
+
+	count := games.CountByOwner(user.ID)
+	if count < botConfig.MaxGames {
+		return "", false
+	}
+
+	return tr(userLanguage(user), "error.max_games", count), true
+}
+
+// startGame sends board of the new game to the chat and stores the game
+func startGame(bot *tgbot.TelegramBot, chatID int, game *Game) error {
+	game.ChatID = chatID
+	game.Shard = shardID(bot)
+	applyChatSettings(game)
+	msg, err := sendMessage(bot, tgbot.SendMessageConfig{
+		ChatID:      tgbot.ChatID(chatID),
+		Text:        renderText(game, tr(game.Language, "game.new")),
 		ReplyMarkup: renderMinefield(game),
 	})
 
 	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send board", "chat_id", chatID, "error", err)
+		bot.SendMessage(tgbot.SendMessageConfig{
+			ChatID: tgbot.ChatID(chatID),
+			Text:   tr(game.Language, "error.send_board"),
+		})
+
+		return err
+	}
+
+	game.MessageID = msg.MessageID
+	sendTextBoard(bot, game, renderText(game, tr(game.Language, "game.new")))
+	games.Set(game)
+	gamesCreated.Inc()
+	slog.Info("Game created",
+		"game", game.Key(),
+		"owner_id", game.OwnerID,
+		"width", game.Minefield.Width,
+		"height", game.Minefield.Height,
+		"mines", game.Minefield.Mines,
+		"seed", game.Minefield.Seed,
+	)
+
+	return nil
+}
+
+func flagAction(req tbf.Request) {
+	game, ok := playableGame(req)
+	if !ok {
 		return
 	}
 
-	games[msg.MessageID] = game
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	game.FlagMode = !game.FlagMode
+	req.QuickMessage(renderMode(game))
+	updateBoard(req.Bot, game, req.Message.From)
 }
 
-func callbackQueryListener(req tbf.CallbackQueryRequest) {
-	var cellData CellCallbackData
-	err := json.Unmarshal([]byte(req.CallbackQuery.Data), &cellData)
-	msg := req.CallbackQuery.Message
-	if err != nil || msg == nil {
+// openAction opens the cell given by its row and column like a tap does,
+// e.g. /open 3 5, for clients where inline buttons are hard to use
+func openAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	row, col, ok := parseOpenArgs(req.Message.Text)
+	if !ok {
+		req.QuickMessage(tr(lang, "open.usage"))
 		return
 	}
 
-	game, ok := games[msg.MessageID]
+	game, ok := playableGame(req)
 	if !ok {
-		req.NoAnswer()
 		return
 	}
 
-	game.Open(cellData.Row, cellData.Col)
+	game.mu.Lock()
+	defer game.mu.Unlock()
 
-	gameState := game.GetState()
-	if gameState == gosweep.GameRunning {
-		req.Bot.EditMessageText(tgbot.EditMessageTextConfig{
-			ChatID:      tgbot.ChatID(msg.Chat.ID),
-			MessageID:   msg.MessageID,
-			Text:        "Minesweeper",
-			ReplyMarkup: renderMinefield(game),
-		})
+	if game.Duel() && req.Message.From.ID != game.CurrentPlayer().ID {
+		req.QuickMessage(tr(lang, "duel.not_your_turn", game.CurrentPlayer().Name))
 		return
 	}
 
-	var notificationText string
-	if gameState == gosweep.GameWin {
-		notificationText = "You won!"
-	} else if gameState == gosweep.GameLose {
-		notificationText = "Game over!"
+	if !game.Minefield.contains(row, col) {
+		req.QuickMessage(tr(lang, "open.bounds", game.Minefield.Height, game.Minefield.Width))
+		return
 	}
 
-	if len(notificationText) == 0 {
-		req.NoAnswer()
+	text, frames, changed := playCell(game, req.Message.From, row, col)
+	if !changed {
+		req.QuickMessage(text)
 		return
 	}
 
-	req.Answer(tgbot.AnswerCallbackQueryConfig{
-		Text:      notificationText,
-		ShowAlert: true,
-	})
+	if !showFrames(req.Bot, game, frames) {
+		return
+	}
 
-	req.Bot.EditMessageText(tgbot.EditMessageTextConfig{
-		ChatID:      tgbot.ChatID(msg.Chat.ID),
-		MessageID:   msg.MessageID,
-		Text:        notificationText,
-		ReplyMarkup: renderMinefield(game),
-	})
+	if notificationText := updateBoard(req.Bot, game, req.Message.From); len(notificationText) > 0 {
+		req.QuickMessage(notificationText)
+	}
 }
 
-func createGame(req tbf.Request) (*gosweep.Minefield, error) {
-	req.QuickMessage("Enter minefield width:")
-	width, err := strconv.ParseInt(req.WaitNext().Message.Text, 10, 32)
-	if err != nil || width < minSize || width > maxSize {
-		return nil, fmt.Errorf("Width should be in between `%d` and `%d`", minSize, maxSize)
+// parseOpenArgs returns cell given by /open command arguments, they are 1-based
+// like coordinates shown in hints while returned ones are 0-based
+func parseOpenArgs(text string) (int, int, bool) {
+	args := commandArgs(text)
+	if len(args) != 2 {
+		return 0, 0, false
 	}
 
-	req.QuickMessage("Enter minefield height:")
-	height, err := strconv.ParseInt(req.WaitNext().Message.Text, 10, 32)
-	if err != nil || height < minSize || height > maxSize {
-		return nil, fmt.Errorf("Height should be in between `%d` and `%d`", minSize, maxSize)
+	row, rowErr := strconv.Atoi(args[0])
+	col, colErr := strconv.Atoi(args[1])
+	if rowErr != nil || colErr != nil {
+		return 0, 0, false
 	}
 
-	req.QuickMessage("Enter mines count:")
-	mines, err := strconv.ParseInt(req.WaitNext().Message.Text, 10, 32)
-	if err != nil {
-		return nil, errors.New("Invalid mines count")
+	return row - 1, col - 1, true
+}
+
+func hintAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	game, ok := playableGame(req)
+	if !ok {
+		return
 	}
 
-	maxMines := int64(float32(width*height) * 0.8)
-	if mines < minMines || mines > maxMines {
-		return nil, fmt.Errorf(
-			"Max mines count for `%d` by `%d` minefield is `%d`, you entered `%d`",
-			width, height, maxMines, mines,
-		)
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if game.Duel() {
+		req.QuickMessage(tr(lang, "duel.no_assist"))
+		return
 	}
 
-	minefield := gosweep.New(int(width), int(height), int(mines))
-	return &minefield, nil
+	row, col, err := useHint(game)
+	if err == errHintLimit {
+		req.QuickMessage(tr(lang, "hint.limit", game.HintsUsed, maxHints))
+		return
+	} else if err != nil {
+		req.QuickMessage(tr(lang, "hint.no_cells"))
+		return
+	}
+
+	autoFlag(game)
+	slog.Debug("Hint used", "game", game.Key(), "row", row, "col", col)
+
+	req.QuickMessage(tr(lang, "hint.used", row+1, col+1))
+	notificationText := updateBoard(req.Bot, game, req.Message.From)
+	if len(notificationText) > 0 {
+		req.QuickMessage(notificationText)
+	}
 }
 
-func renderMinefield(game *gosweep.Minefield) *tgbot.ReplyMarkup {
-	field := game.GetField()
-	buttons := make([][]tgbot.InlineKeyboardButton, game.GetHeigth())
-	for row := 0; row < game.GetHeigth(); row++ {
-		buttons[row] = make([]tgbot.InlineKeyboardButton, game.GetWidth())
-		for col := 0; col < game.GetWidth(); col++ {
-			cell := field[row][col]
-			callbackBytes, _ := json.Marshal(CellCallbackData{
-				Row: row,
-				Col: col,
-			})
+// useHint opens random safe cell of the game counting it against hints limit,
+// game lock should be held
+func useHint(game *Game) (int, int, error) {
+	if game.HintsUsed >= maxHints {
+		return 0, 0, errHintLimit
+	}
 
-			buttons[row][col] = tgbot.InlineKeyboardButton{
-				Text:         renderCell(cell),
-				CallbackData: string(callbackBytes),
-			}
-		}
+	row, col, ok := game.Minefield.SafeCell()
+	if !ok {
+		return 0, 0, errNoSafeCell
+	}
+
+	game.HintsUsed++
+	game.Minefield.Open(row, col)
+	return row, col, nil
+}
+
+// analyzeAction reports the safest cell of the current game without opening it
+func analyzeAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	game, ok := commandGame(req)
+	if !ok {
+		req.QuickMessage(tr(lang, "game.not_found"))
+		return
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if game.Duel() {
+		req.QuickMessage(tr(lang, "duel.no_assist"))
+		return
+	}
+
+	row, col, probability, sureMines, ok := game.Minefield.SafestCell()
+	if !ok {
+		req.QuickMessage(tr(lang, "analyze.failed"))
+		return
 	}
 
-	return tgbot.InlineKeyboardMarkup(buttons)
+	var lines []string
+	if probability < probabilityEpsilon {
+		lines = append(lines, tr(lang, "analyze.safe", row+1, col+1))
+	} else {
+		lines = append(lines, tr(lang, "analyze.safest", row+1, col+1, int(math.Round(probability*100))))
+	}
+
+	if sureMines > 0 {
+		lines = append(lines, tr(lang, "analyze.mines", sureMines))
+	}
+
+	req.QuickMessage(strings.Join(lines, "\n"))
+}
+
+func undoAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	game, ok := playableGame(req)
+	if !ok {
+		return
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if game.Duel() {
+		req.QuickMessage(tr(lang, "duel.no_assist"))
+		return
+	}
+
+	if !game.Minefield.Undo() {
+		req.QuickMessage(tr(lang, "undo.empty"))
+		return
+	}
+
+	slog.Debug("Move undone", "game", game.Key())
+	updateBoard(req.Bot, game, req.Message.From)
+}
+
+func exportAction(req tbf.Request) {
+	game, ok := commandGame(req)
+	if !ok {
+		game, ok = games.GetFinished(shardID(req.Bot), req.Message.Chat.ID)
+	}
+
+	if !ok {
+		req.QuickMessage(tr(userLanguage(req.Message.From), "game.not_found"))
+		return
+	}
+
+	game.mu.Lock()
+	grid := renderGrid(game.Minefield)
+	game.mu.Unlock()
+
+	req.QuickMessageMD("```\n" + grid + "\n```")
+}
+
+func boardAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	game, ok := playableGame(req)
+	if !ok {
+		return
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	// game could be finished, cancelled or replaced while this command waited for the lock
+	if current, ok := games.Get(game.Key()); !ok || current != game {
+		req.QuickMessage(tr(lang, "game.not_found"))
+		return
+	}
+
+	if !game.Playable(req.Message.From.ID) {
+		req.QuickMessage(tr(lang, "game.not_yours"))
+		return
+	}
+
+	msg, err := req.Bot.SendMessage(tgbot.SendMessageConfig{
+		ChatID:      tgbot.ChatID(game.ChatID),
+		Text:        renderText(game, tr(game.Language, "game.title")),
+		ReplyMarkup: renderMinefield(game),
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send board", "game", game.Key(), "error", err)
+		return
+	}
+
+	oldConfig := game.EditConfig()
+	games.Delete(game.Key())
+	game.MessageID = msg.MessageID
+	game.signature = ""
+	games.Set(game)
+	slog.Info("Board moved", "game", game.Key(), "old_message_id", oldConfig.MessageID)
+
+	oldConfig.Text = tr(game.Language, "game.moved")
+	if err := editMessage(req.Bot, oldConfig); err != nil {
+		slog.Error("Unable to update board", "game", game.Key(), "error", err)
+	}
+
+	moveTextBoard(req.Bot, game, renderText(game, tr(game.Language, "game.title")))
 }
 
-func renderCell(cell gosweep.Cell) string {
-	typeChars := map[int]string{
-		gosweep.TypeEmpty: " ",
-		gosweep.Type1:     "1️⃣",
-		gosweep.Type2:     "2️⃣",
-		gosweep.Type3:     "3️⃣",
-		gosweep.Type4:     "4️⃣",
-		gosweep.Type5:     "5️⃣",
-		gosweep.Type6:     "6️⃣",
-		gosweep.Type7:     "7️⃣",
-		gosweep.Type8:     "8️⃣",
-		gosweep.TypeMine:  "⚫️",
+func cancelAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	game, ok := playableGame(req)
+	if !ok {
+		return
 	}
 
-	stateChars := map[int]string{
-		gosweep.StateClosed:  "⬜️",
-		gosweep.StateFlagged: "ℹ️",
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	// game could be finished or cancelled while this command waited for the lock
+	if _, ok := games.Get(game.Key()); !ok {
+		req.QuickMessage(tr(lang, "game.not_found"))
+		return
 	}
 
-	if val, ok := stateChars[cell.State]; ok {
-		return val
+	games.Delete(game.Key())
+	slog.Info("Game cancelled", "game", game.Key())
+
+	config := game.EditConfig()
+	config.Text = tr(game.Language, "game.cancelled")
+	if err := editMessage(req.Bot, config); err != nil {
+		slog.Error("Unable to update board", "game", game.Key(), "error", err)
 	}
 
-	if val, ok := typeChars[cell.Type]; ok {
-		return val
+	updateTextBoard(req.Bot, game, config.Text)
+}
+
+// abandonAllAction removes all active games of the sender without touching
+// their boards, admins can remove games of another user given by ID
+func abandonAllAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	ownerID := req.Message.From.ID
+	if args := commandArgs(req.Message.Text); len(args) > 0 {
+		if !isAdmin(req.Message.From.ID) {
+			req.QuickMessage(tr(lang, "abandon.not_admin"))
+			return
+		}
+
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			req.QuickMessage(tr(lang, "abandon.user_id"))
+			return
+		}
+
+		ownerID = id
 	}
 
-	return fmt.Sprint(cell.Type)
+	deleted := games.DeleteByOwner(ownerID)
+	slog.Info("Games abandoned", "user_id", req.Message.From.ID, "owner_id", ownerID, "count", deleted)
+	req.QuickMessage(tr(lang, "abandon.deleted", deleted))
+}
+
+func scoreboardAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	scores := scoreboard.Top(scoreboardSize)
+	if len(scores) == 0 {
+		req.QuickMessage(tr(lang, "scoreboard.empty"))
+		return
+	}
+
+	lines := []string{"*" + tr(lang, "scoreboard.title") + "*"}
+	for i, score := range scores {
+		lines = append(lines, fmt.Sprintf("%d. %s — %d", i+1, escapeMarkdown(score.Name), score.Wins))
+	}
+
+	req.QuickMessageMD(strings.Join(lines, "\n"))
+}
+
+func statsAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	score, ok := scoreboard.Get(req.Message.From.ID)
+	if !ok {
+		req.QuickMessage(tr(lang, "stats.empty"))
+		return
+	}
+
+	lines := []string{
+		"*" + tr(lang, "stats.title") + "*",
+		tr(lang, "stats.games", score.Games()),
+		tr(lang, "stats.wins", score.Wins),
+		tr(lang, "stats.losses", score.Losses),
+		tr(lang, "stats.abandoned", score.Abandoned),
+		tr(lang, "stats.win_rate", score.WinRate()),
+		tr(lang, "stats.streak", score.Streak, score.BestStreak),
+	}
+
+	if len(score.BestTimes) > 0 {
+		lines = append(lines, "", "*"+tr(lang, "stats.best_times")+"*")
+
+		difficulties := make([]string, 0, len(score.BestTimes))
+		for difficulty := range score.BestTimes {
+			difficulties = append(difficulties, difficulty)
+		}
+
+		sort.Strings(difficulties)
+		for _, difficulty := range difficulties {
+			lines = append(lines, fmt.Sprintf("%s — %s", difficulty, score.BestTimes[difficulty]))
+		}
+	}
+
+	req.QuickMessageMD(strings.Join(lines, "\n"))
+}
+
+func themeAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	args := commandArgs(req.Message.Text)
+	if len(args) == 0 {
+		req.QuickMessageMD(tr(lang, "theme.current",
+			getThemeName(settings.Get(req.Message.From.ID).Theme),
+			strings.Join(themeNames(), ", "),
+		))
+
+		return
+	}
+
+	name := strings.ToLower(args[0])
+	if _, ok := themes[name]; !ok {
+		req.QuickMessageMD(tr(lang, "theme.unknown", strings.Join(themeNames(), ", ")))
+		return
+	}
+
+	settings.Update(req.Message.From.ID, func(s *UserSettings) {
+		s.Theme = name
+	})
+
+	if applyGameTheme(req, name) {
+		req.QuickMessageMD(tr(lang, "theme.applied", name))
+		return
+	}
+
+	req.QuickMessageMD(tr(lang, "theme.set", name))
+}
+
+// applyGameTheme switches theme of the sender's running game in the chat
+// and re-renders its board, cells of the game are left untouched
+func applyGameTheme(req tbf.Request, name string) bool {
+	game, ok := commandGame(req)
+	if !ok || game.OwnerID != req.Message.From.ID {
+		return false
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if game.Minefield.State != GameRunning {
+		return false
+	}
+
+	game.Theme = name
+	updateBoard(req.Bot, game, req.Message.From)
+	slog.Debug("Game theme changed", "game", game.Key(), "theme", name)
+	return true
+}
+
+func themesAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	lines := []string{tr(lang, "theme.preview")}
+	var buttons [][]tgbot.InlineKeyboardButton
+	for _, name := range themeNames() {
+		lines = append(lines, name+": "+renderThemeSample(themes[name]))
+		buttons = append(buttons, []tgbot.InlineKeyboardButton{{
+			Text: name,
+			CallbackData: encodeCallbackData(CellCallbackData{
+				Action: actionTheme,
+				Value:  name,
+			}),
+		}})
+	}
+
+	_, err := req.Bot.SendMessage(tgbot.SendMessageConfig{
+		ChatID:      tgbot.ChatID(req.Message.Chat.ID),
+		Text:        strings.Join(lines, "\n"),
+		ReplyMarkup: tgbot.InlineKeyboardMarkup(buttons),
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send themes", "chat_id", req.Message.Chat.ID, "error", err)
+	}
+}
+
+func themeListener(req tbf.CallbackQueryRequest, name string) {
+	lang := userLanguage(req.CallbackQuery.From)
+	if _, ok := themes[name]; !ok {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text: tr(lang, "theme.unknown", strings.Join(themeNames(), ", ")),
+		})
+
+		return
+	}
+
+	settings.Update(req.CallbackQuery.From.ID, func(s *UserSettings) {
+		s.Theme = name
+	})
+
+	req.Answer(tgbot.AnswerCallbackQueryConfig{
+		Text: tr(lang, "theme.selected", name),
+	})
+}
+
+func assistAction(req tbf.Request) {
+	var assist bool
+	settings.Update(req.Message.From.ID, func(s *UserSettings) {
+		s.Assist = !s.Assist
+		assist = s.Assist
+	})
+
+	key := "assist.disabled"
+	if assist {
+		key = "assist.enabled"
+	}
+
+	req.QuickMessage(tr(userLanguage(req.Message.From), key))
+}
+
+func verboseAction(req tbf.Request) {
+	var verbose bool
+	settings.Update(req.Message.From.ID, func(s *UserSettings) {
+		s.Verbose = !s.Verbose
+		verbose = s.Verbose
+	})
+
+	key := "verbose.disabled"
+	if verbose {
+		key = "verbose.enabled"
+	}
+
+	req.QuickMessage(tr(userLanguage(req.Message.From), key))
+}
+
+func callbackQueryListener(req tbf.CallbackQueryRequest) {
+	callbackQueries.Inc()
+	if limiter != nil && !limiter.Allow(req.CallbackQuery.From.ID) {
+		slog.Debug("Callback rate limited", "user_id", req.CallbackQuery.From.ID)
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text:      tr(userLanguage(req.CallbackQuery.From), "error.rate_limited"),
+			ShowAlert: true,
+		})
+
+		return
+	}
+
+	cellData, err := decodeCallbackData(req.CallbackQuery.Data)
+	if err != nil {
+		slog.Warn("Invalid callback data", "data", req.CallbackQuery.Data, "error", err)
+		return
+	}
+
+	if cellData.Action == actionRestart {
+		restartListener(req)
+		return
+	} else if cellData.Action == actionPlay {
+		playListener(req, cellData.Value)
+		return
+	} else if cellData.Action == actionWatch {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text:      tr(userLanguage(req.CallbackQuery.From), "watch.read_only"),
+			ShowAlert: true,
+		})
+
+		return
+	} else if cellData.Action == actionTheme {
+		themeListener(req, cellData.Value)
+		return
+	} else if cellData.Action == actionNoop {
+		req.NoAnswer()
+		return
+	}
+
+	key := callbackKey(shardID(req.Bot), req.CallbackQuery)
+	game, ok := games.Get(key)
+	if !ok {
+		if finished, ok := finishedBoard(req); ok {
+			req.Answer(*finishedAnswer(req.CallbackQuery.From, finished))
+			return
+		}
+
+		slog.Debug("Game not found", "game", key)
+		expireBoard(req)
+		return
+	}
+
+	if !game.Playable(req.CallbackQuery.From.ID) {
+		slog.Debug("Locked game tapped by another user",
+			"game", key,
+			"owner_id", game.OwnerID,
+			"user_id", req.CallbackQuery.From.ID,
+		)
+
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text:      tr(userLanguage(req.CallbackQuery.From), "game.not_yours"),
+			ShowAlert: true,
+		})
+
+		return
+	}
+
+	if answer := playCallback(req.Bot, game, req.CallbackQuery.From, cellData); answer != nil {
+		req.Answer(*answer)
+	} else {
+		req.NoAnswer()
+	}
+}
+
+// playCallback makes the move of the callback holding the game lock, it returns
+// answer for the callback query or nil when there is nothing to tell
+func playCallback(bot *tgbot.TelegramBot, game *Game, player *tgbot.User, cellData CellCallbackData) *tgbot.AnswerCallbackQueryConfig {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	// game could be finished by concurrent callback while this one waited for the lock
+	if game.Minefield.State != GameRunning {
+		return finishedAnswer(player, game)
+	}
+
+	// or cancelled by /cancel which leaves it running but removes from the store
+	key := game.Key()
+	if current, ok := games.Get(key); !ok || current != game {
+		return nil
+	}
+
+	if game.Duel() && player.ID != game.CurrentPlayer().ID {
+		return &tgbot.AnswerCallbackQueryConfig{
+			Text:      tr(userLanguage(player), "duel.not_your_turn", game.CurrentPlayer().Name),
+			ShowAlert: true,
+		}
+	}
+
+	isControl := cellData.Action == actionToggleMode || cellData.Action == actionGiveUp || cellData.Action == actionScroll
+	if !isControl && !game.Minefield.contains(cellData.Row, cellData.Col) {
+		slog.Warn("Callback cell out of bounds", "game", key, "row", cellData.Row, "col", cellData.Col)
+		return nil
+	}
+
+	lang := userLanguage(player)
+	var toast string
+	var alert bool
+	if cellData.Action == actionToggleMode {
+		game.FlagMode = !game.FlagMode
+		return &tgbot.AnswerCallbackQueryConfig{
+			Text: renderMode(game),
+		}
+	} else if cellData.Action == actionGiveUp {
+		if !game.Minefield.GiveUp() {
+			return nil
+		}
+
+		game.GaveUp = true
+		slog.Debug("Game given up", "game", key, "user_id", player.ID)
+	} else if cellData.Action == actionScroll {
+		if !scrollViewport(game, cellData.Value) {
+			return nil
+		}
+	} else if game.FlagMode {
+		game.Minefield.Flag(cellData.Row, cellData.Col)
+		toast = flagToast(lang, game.Minefield.Field[cellData.Row][cellData.Col].State, cellData.Row, cellData.Col)
+		slog.Debug("Cell flagged", "game", key, "row", cellData.Row, "col", cellData.Col)
+	} else {
+		chorded := game.Minefield.Field[cellData.Row][cellData.Col].State == StateOpened
+		text, frames, changed := playCell(game, player, cellData.Row, cellData.Col)
+		if !changed || !showFrames(bot, game, frames) {
+			return &tgbot.AnswerCallbackQueryConfig{
+				Text: text,
+			}
+		}
+
+		toast = text
+		alert = botConfig.AlertEvents && significantMove(game.Minefield.Field[cellData.Row][cellData.Col], chorded)
+	}
+
+	notificationText := updateBoard(bot, game, player)
+	if len(notificationText) == 0 {
+		if len(toast) > 0 {
+			return &tgbot.AnswerCallbackQueryConfig{
+				Text:      toast,
+				ShowAlert: alert,
+			}
+		}
+
+		return nil
+	}
+
+	return &tgbot.AnswerCallbackQueryConfig{
+		Text:      notificationText,
+		ShowAlert: true,
+	}
+}
+
+// playCell opens closed cell or chords opened one like a tap in open mode,
+// description of the move is returned with animation frames of the move and
+// if it changed anything. Game lock should be held
+func playCell(game *Game, player *tgbot.User, row, col int) (string, []tgbot.EditMessageTextConfig, bool) {
+	lang := userLanguage(player)
+	cell := game.Minefield.Field[row][col]
+	if cell.State == StateFlagged {
+		return tr(lang, "toast.blocked", row+1, col+1), nil, false
+	}
+
+	if needsConfirmation(game, row, col) {
+		game.PendingTap = &Position{Row: row, Col: col}
+		return tr(lang, "game.confirm_start"), nil, false
+	}
+
+	game.PendingTap = nil
+	var cells []int
+	var text string
+	if cell.State == StateOpened {
+		cells = game.Minefield.Chord(row, col)
+		text = tr(lang, "toast.chorded", row+1, col+1, len(cells))
+		slog.Debug("Cell chorded", "game", game.Key(), "row", row, "col", col)
+	} else {
+		cells = game.Minefield.Open(row, col)
+		text = tr(lang, "toast.opened", row+1, col+1)
+		slog.Debug("Cell opened", "game", game.Key(), "row", row, "col", col)
+	}
+
+	if len(cells) == 0 {
+		return tr(lang, "toast.nothing"), nil, false
+	}
+
+	frames := floodFrames(game, player, row, col, cells)
+
+	if game.Duel() {
+		passTurn(game, len(cells))
+	}
+
+	autoFlag(game)
+	return text, frames, true
+}
+
+// significantMove checks if the move deserves alert instead of toast when
+// alert_events is enabled, these are chords and opened numbers of 4 and more
+func significantMove(cell Cell, chorded bool) bool {
+	return chorded || cell.Type >= Type4 && cell.Type != TypeMine
+}
+
+// flagToast describes new state of the cell marked in flag mode
+func flagToast(lang string, state, row, col int) string {
+	switch state {
+	case StateFlagged:
+		return tr(lang, "toast.flagged", row+1, col+1)
+	case StateQuestion:
+		return tr(lang, "toast.question", row+1, col+1)
+	case StateOpened:
+		return tr(lang, "toast.nothing")
+	}
+
+	return tr(lang, "toast.unflagged", row+1, col+1)
+}
+
+// needsConfirmation checks if the first tap of the game should be repeated
+// to be sure it's not accidental, it's enabled by confirm_first_tap option
+func needsConfirmation(game *Game, row, col int) bool {
+	if !botConfig.ConfirmFirstTap || game.Minefield.countState(StateOpened) > 0 {
+		return false
+	}
+
+	pending := game.PendingTap
+	return pending == nil || pending.Row != row || pending.Col != col
+}
+
+// autoFlag flags obvious mines when game owner has assist mode enabled
+func autoFlag(game *Game) {
+	if !settings.Get(game.OwnerID).Assist {
+		return
+	}
+
+	if flagged := game.Minefield.AutoFlag(); flagged > 0 {
+		slog.Debug("Mines auto flagged", "game", game.Key(), "count", flagged)
+	}
+}
+
+// finishedBoard returns finished game whose board was tapped, it's kept
+// only for the last finished game of the chat and for inline boards
+func finishedBoard(req tbf.CallbackQueryRequest) (*Game, bool) {
+	message := req.CallbackQuery.Message
+	if message == nil {
+		return games.GetFinishedInline(req.CallbackQuery.InlineMessageID)
+	}
+
+	game, ok := games.GetFinished(shardID(req.Bot), message.Chat.ID)
+	if !ok || game.MessageID != message.MessageID {
+		return nil, false
+	}
+
+	return game, true
+}
+
+// finishedAnswer tells outcome of the finished game leaving its board untouched
+func finishedAnswer(player *tgbot.User, game *Game) *tgbot.AnswerCallbackQueryConfig {
+	textKey := "game.already_lost"
+	if game.Minefield.State == GameWin {
+		textKey = "game.already_won"
+	}
+
+	return &tgbot.AnswerCallbackQueryConfig{
+		Text:      tr(userLanguage(player), textKey),
+		ShowAlert: true,
+	}
+}
+
+// expireBoard tells the user that board's game is gone and removes
+// keyboard from the board message
+func expireBoard(req tbf.CallbackQueryRequest) {
+	lang := userLanguage(req.CallbackQuery.From)
+	req.Answer(tgbot.AnswerCallbackQueryConfig{
+		Text:      tr(lang, "game.expired"),
+		ShowAlert: true,
+	})
+
+	config := tgbot.EditMessageTextConfig{
+		InlineMessageID: req.CallbackQuery.InlineMessageID,
+		Text:            tr(lang, "game.expired"),
+	}
+
+	if req.CallbackQuery.Message != nil {
+		config.ChatID = tgbot.ChatID(req.CallbackQuery.Message.Chat.ID)
+		config.MessageID = req.CallbackQuery.Message.MessageID
+		config.Text = req.CallbackQuery.Message.Text + "\n\n" + config.Text
+	}
+
+	if err := editMessage(req.Bot, config); err != nil {
+		slog.Error("Unable to expire board", "game", callbackKey(shardID(req.Bot), req.CallbackQuery), "error", err)
+	}
+}
+
+// passTurn credits safe cells opened by the move to current player
+// and passes turn to the next one while game is running
+func passTurn(game *Game, opened int) {
+	if game.Minefield.State == GameLose {
+		opened--
+	}
+
+	game.CurrentPlayer().Revealed += opened
+	if game.Minefield.State == GameRunning {
+		game.Turn = (game.Turn + 1) % len(game.Players)
+	}
+}
+
+func restartListener(req tbf.CallbackQueryRequest) {
+	if req.CallbackQuery.Message == nil {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text: tr(userLanguage(req.CallbackQuery.From), "restart.use_play"),
+		})
+
+		return
+	}
+
+	chatID := req.CallbackQuery.Message.Chat.ID
+	difficulty, ok := games.GetLast(shardID(req.Bot), chatID)
+	if !ok {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text: tr(userLanguage(req.CallbackQuery.From), "restart.use_play"),
+		})
+
+		return
+	}
+
+	callbackStartGame(req, difficulty)
+}
+
+// callbackStartGame starts game in the chat of the callback message
+func callbackStartGame(req tbf.CallbackQueryRequest, difficulty Difficulty) {
+	chatID := req.CallbackQuery.Message.Chat.ID
+	if _, busy := chatBusyGame(shardID(req.Bot), chatID); busy {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text:      tr(userLanguage(req.CallbackQuery.From), "error.chat_busy"),
+			ShowAlert: true,
+		})
+
+		return
+	}
+
+	if text, reached := gameLimitText(req.CallbackQuery.From); reached {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text:      text,
+			ShowAlert: true,
+		})
+
+		return
+	}
+
+	req.NoAnswer()
+	minefield := newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, newSeed())
+	startGame(req.Bot, chatID, newGame(req.CallbackQuery.From, minefield))
+}
+
+func playListener(req tbf.CallbackQueryRequest, name string) {
+	difficulty, ok := difficulties[name]
+	if !ok || req.CallbackQuery.Message == nil {
+		req.Answer(tgbot.AnswerCallbackQueryConfig{
+			Text: tr(userLanguage(req.CallbackQuery.From), "restart.use_play"),
+		})
+
+		return
+	}
+
+	callbackStartGame(req, difficulty)
+}
+
+// recordResult updates score of the player who finished the game, text
+// announcing new best or broken win streak is returned when there's one.
+// Training games are counted in metrics only
+func recordResult(game *Game, player *tgbot.User, elapsed time.Duration) string {
+	training := len(game.Training) > 0
+	var streakText string
+	switch game.Minefield.State {
+	case GameWin:
+		gamesWon.Inc()
+		if training {
+			break
+		}
+
+		streak, best := scoreboard.AddWin(player.ID, userName(player), game.Minefield.Difficulty(), elapsed)
+		if best && streak > 1 {
+			streakText = tr(game.Language, "streak.best", streak)
+		}
+	case GameLose:
+		gamesLost.Inc()
+		if training {
+			break
+		}
+
+		if streak := scoreboard.AddLoss(player.ID, userName(player)); streak > 1 {
+			streakText = tr(game.Language, "streak.broken", streak)
+		}
+	}
+
+	return streakText
+}
+
+// updateBoard edits board message with current game state and finishes the game
+// when it's over, notification text is returned for finished games only
+func updateBoard(bot *tgbot.TelegramBot, game *Game, player *tgbot.User) string {
+	game.LastMoveAt = time.Now()
+	elapsed := time.Since(game.CreatedAt).Round(time.Second)
+
+	wonKey, lostKey := "game.won", "game.lost"
+	if game.GaveUp {
+		lostKey = "game.gave_up"
+	}
+
+	var args []any
+	if game.Duel() {
+		wonKey, lostKey = "duel.won", "duel.lost"
+		if game.GaveUp {
+			lostKey = "duel.gave_up"
+		}
+
+		args = append(args, userName(player))
+	}
+
+	args = append(args, elapsed)
+
+	var notificationText string
+	streakText := recordResult(game, player, elapsed)
+	if game.Minefield.State == GameWin {
+		notificationText = tr(game.Language, wonKey, args...)
+		slog.Info("Game won", "game", game.Key(), "user_id", player.ID, "elapsed", elapsed)
+	} else if game.Minefield.State == GameLose {
+		notificationText = tr(game.Language, lostKey, args...)
+		slog.Info("Game lost", "game", game.Key(), "user_id", player.ID, "elapsed", elapsed)
+	}
+
+	if len(notificationText) > 0 && len(game.Training) == 0 {
+		outcomes.Add(game.Minefield.Difficulty(), game.Minefield.State == GameWin)
+	}
+
+	if len(game.Daily) > 0 && len(notificationText) > 0 {
+		dailyResults.Add(game.Daily, DailyResult{
+			UserID:  player.ID,
+			Name:    userName(player),
+			Won:     game.Minefield.State == GameWin,
+			Elapsed: elapsed,
+		})
+	}
+
+	text := renderText(game, tr(game.Language, "game.title"))
+	if len(notificationText) > 0 {
+		text = renderText(game, notificationText) + "\n\n" + renderSummary(game)
+	}
+
+	if len(streakText) > 0 {
+		text += "\n" + streakText
+	}
+
+	config := game.EditConfig()
+	config.Text = text
+	config.ReplyMarkup = renderMinefield(game)
+	signature := boardSignature(config.Text, config.ReplyMarkup)
+	if signature == game.signature {
+		slog.Debug("Board not changed", "game", game.Key())
+	} else if err := editMessage(bot, config); err != nil {
+		if !dropDeletedBoard(game, err) {
+			slog.Error("Unable to update board", "game", game.Key(), "error", err)
+		}
+	} else {
+		game.signature = signature
+		updateMirrors(bot, game, text)
+		updateTextBoard(bot, game, text)
+	}
+
+	if len(notificationText) > 0 {
+		replays.Add(game.OwnerID, newReplay(game))
+		games.Finish(game)
+	}
+
+	if len(streakText) > 0 {
+		notificationText += "\n" + streakText
+	}
+
+	return notificationText
+}
+
+// dropDeletedBoard removes game whose board message was deleted so it's not
+// edited anymore, it reports if the edit error means that
+func dropDeletedBoard(game *Game, err error) bool {
+	if !isMessageNotFoundError(err) {
+		return false
+	}
+
+	games.Delete(game.Key())
+	slog.Info("Board message deleted, game removed", "game", game.Key())
+	return true
+}
+
+func evictGames(ttl time.Duration) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if evicted := games.Evict(time.Now().Add(-ttl)); evicted > 0 {
+			slog.Info("Expired games evicted", "count", evicted)
+		}
+	}
+}
+
+func createGame(req tbf.Request) (*Minefield, error) {
+	lang := userLanguage(req.Message.From)
+	args, seed, err := parseSeed(commandArgs(req.Message.Text))
+	if err != nil {
+		return nil, err
+	}
+
+	args, noGuess := parseOption(args, noGuessArg)
+	args, manual := parseOption(args, manualArg)
+	minefield, err := parseMinefield(req, lang, args, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if noGuess {
+		minefield = newNoGuessMinefield(minefield.Width, minefield.Height, minefield.Mines, minefield.Seed)
+	}
+
+	minefield.Manual = manual
+	return minefield, nil
+}
+
+// parseMinefield creates minefield from preset name or dimensions given in arguments,
+// missing parameters are prompted from the user
+func parseMinefield(req tbf.Request, lang string, args []string, seed int64) (*Minefield, error) {
+	if len(args) > 0 {
+		if difficulty, ok := difficulties[strings.ToLower(args[0])]; ok {
+			return newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, seed), nil
+		}
+	} else if difficulty, ok := chatDifficulty(shardID(req.Bot), req.Message.Chat.ID); ok {
+		return newMinefield(difficulty.Width, difficulty.Height, difficulty.Mines, seed), nil
+	}
+
+	match := playGameRe.FindStringSubmatch(strings.Join(args, " "))
+	if match == nil {
+		retry := func(err error, attemptsLeft int) {
+			req.QuickMessageMD(tr(lang, "prompt.retry", errorText(lang, err), attemptsLeft))
+		}
+
+		return readMinefield(askNumber(req), retry, lang, seed)
+	}
+
+	width, err := strconv.ParseInt(match[1], 10, 32)
+	if err = validateWidth(width, err); err != nil {
+		return nil, err
+	}
+
+	height, err := strconv.ParseInt(match[2], 10, 32)
+	if err = validateHeight(height, err); err != nil {
+		return nil, err
+	} else if err = validateKeyboard(width, height); err != nil {
+		return nil, err
+	}
+
+	mines, err := strconv.ParseInt(match[3], 10, 32)
+	if err = validateMines(width, height, mines, err); err != nil {
+		return nil, err
+	}
+
+	return newMinefield(int(width), int(height), int(mines), seed), nil
+}
+
+// parseSeed extracts seed:<number> argument, random seed is returned when it's missing
+func parseSeed(args []string) ([]string, int64, error) {
+	rest := make([]string, 0, len(args))
+	seed := newSeed()
+	for _, arg := range args {
+		if !strings.HasPrefix(strings.ToLower(arg), seedPrefix) {
+			rest = append(rest, arg)
+			continue
+		}
+
+		var err error
+		seed, err = strconv.ParseInt(arg[len(seedPrefix):], 10, 64)
+		if err != nil {
+			return nil, 0, SeedParseError{Err: err}
+		}
+	}
+
+	return rest, seed, nil
+}
+
+// parseOption extracts option argument like noguess
+func parseOption(args []string, option string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if strings.ToLower(arg) == option {
+			found = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	return rest, found
+}
+
+// readMinefield builds minefield from answers returned by ask for each prompt
+// offering suggested values, retry is called for each rejected answer
+func readMinefield(ask func(prompt string, suggestions []int64) string, retry func(err error, attemptsLeft int), lang string, seed int64) (*Minefield, error) {
+	width, err := readValue(ask, retry, tr(lang, "prompt.width"), sizeSuggestions(), func(width int64, err error) error {
+		return validateWidth(width, err)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := readValue(ask, retry, tr(lang, "prompt.height"), sizeSuggestions(), func(height int64, err error) error {
+		if err = validateHeight(height, err); err != nil {
+			return err
+		}
+
+		return validateKeyboard(width, height)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	mines, err := readValue(ask, retry, tr(lang, "prompt.mines"), minesSuggestions(width, height), func(mines int64, err error) error {
+		return validateMines(width, height, mines, err)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newMinefield(int(width), int(height), int(mines), seed), nil
+}
+
+// askNumber returns ask func sending prompt with suggested values as reply
+// keyboard buttons, a tap sends the value as a regular message answering it
+func askNumber(req tbf.Request) func(prompt string, suggestions []int64) string {
+	return func(prompt string, suggestions []int64) string {
+		_, err := req.Bot.SendMessage(tgbot.SendMessageConfig{
+			ChatID:      tgbot.ChatID(req.Message.Chat.ID),
+			Text:        prompt,
+			ReplyMarkup: tgbot.ReplyKeyboardMarkup(suggestionButtons(suggestions), true, true, true),
+		})
+
+		if err != nil {
+			apiErrors.Inc()
+			slog.Error("Unable to send prompt", "chat_id", req.Message.Chat.ID, "error", err)
+		}
+
+		return req.WaitNext().Message.Text
+	}
+}
+
+// suggestionButtons returns reply keyboard buttons sending suggested values
+func suggestionButtons(suggestions []int64) [][]tgbot.KeyboardButton {
+	var buttons [][]tgbot.KeyboardButton
+	for start := 0; start < len(suggestions); start += maxKeyboardWidth {
+		row := make([]tgbot.KeyboardButton, 0, maxKeyboardWidth)
+		for _, suggestion := range suggestions[start:min(start+maxKeyboardWidth, len(suggestions))] {
+			row = append(row, tgbot.KeyboardButton{Text: strconv.FormatInt(suggestion, 10)})
+		}
+
+		buttons = append(buttons, row)
+	}
+
+	return buttons
+}
+
+// readValue asks for a number offering suggested values, the question is
+// repeated until validate accepts the answer or attempts run out
+func readValue(ask func(prompt string, suggestions []int64) string, retry func(err error, attemptsLeft int), prompt string, suggestions []int64, validate func(int64, error) error) (int64, error) {
+	for attempt := 1; ; attempt++ {
+		value, err := readNumber(ask, prompt, suggestions)
+		if err == errGameCancelled {
+			return 0, err
+		}
+
+		err = validate(value, err)
+		if err == nil || attempt == maxPromptAttempts {
+			return value, err
+		}
+
+		retry(err, maxPromptAttempts-attempt)
+	}
+}
+
+// readNumber returns number typed or chosen with a suggestion button in reply
+// to the prompt, errGameCancelled is returned for /cancel command
+func readNumber(ask func(prompt string, suggestions []int64) string, prompt string, suggestions []int64) (int64, error) {
+	text := ask(prompt, suggestions)
+	if isCommand(text, "cancel") {
+		return 0, errGameCancelled
+	}
+
+	return strconv.ParseInt(text, 10, 32)
+}
+
+func sizeSuggestions() []int64 {
+	suggestions := make([]int64, 0, maxSize-minSize+1)
+	for size := int64(minSize); size <= maxSize; size++ {
+		suggestions = append(suggestions, size)
+	}
+
+	return suggestions
+}
+
+// minesSuggestions returns valid mines counts for boards from easy to hard ones
+func minesSuggestions(width, height int64) []int64 {
+	var suggestions []int64
+	for _, density := range suggestedDensities {
+		mines := max(minMines, int64(float64(width*height)*density))
+		if !slices.Contains(suggestions, mines) && validateMines(width, height, mines, nil) == nil {
+			suggestions = append(suggestions, mines)
+		}
+	}
+
+	return suggestions
+}
+
+func difficultyNames() []string {
+	names := make([]string, 0, len(difficulties))
+	for name := range difficulties {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return difficulties[names[i]].Mines < difficulties[names[j]].Mines
+	})
+
+	return names
+}
+
+func commandArgs(text string) []string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return fields[1:]
+}
+
+func isCommand(text, command string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	name := strings.SplitN(fields[0], "@", 2)[0]
+	return name == "/"+command
+}
+
+func userName(user *tgbot.User) string {
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
 }
 
 func checkError(e error) {
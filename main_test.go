@@ -0,0 +1,647 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+// withConfig replaces bot config for the test
+func withConfig(t *testing.T, config BotConfig) {
+	t.Helper()
+	previous := botConfig
+	t.Cleanup(func() { botConfig = previous })
+	botConfig = config
+}
+
+// testRequest builds private chat message request without a bot
+func testRequest(userID int, text string) tbf.Request {
+	user := &tgbot.User{ID: userID}
+	return tbf.Request{
+		Message: &tgbot.Message{
+			From: user,
+			Chat: &tgbot.Chat{ID: userID},
+			Text: text,
+		},
+	}
+}
+
+func TestUseHint(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		game := &Game{Minefield: newMinefield(8, 8, 10, seed)}
+		row, col, err := useHint(game)
+		if err != nil {
+			t.Fatalf("seed %d: useHint() error = %v", seed, err)
+		}
+
+		if game.Minefield.Field[row][col].Type == TypeMine || game.Minefield.State == GameLose {
+			t.Errorf("seed %d: hint opened a mine at %d,%d", seed, row, col)
+		}
+
+		if _, _, err := useHint(game); err != errHintLimit {
+			t.Errorf("seed %d: second useHint() error = %v, want %v", seed, err, errHintLimit)
+		}
+
+		if game.HintsUsed != maxHints {
+			t.Errorf("seed %d: HintsUsed = %d, want %d", seed, game.HintsUsed, maxHints)
+		}
+	}
+}
+
+func TestUseHintNoSafeCells(t *testing.T) {
+	game := &Game{Minefield: testMinefield(".*", "**")}
+	game.Minefield.Open(0, 0)
+	if _, _, err := useHint(game); err != errNoSafeCell {
+		t.Errorf("useHint() error = %v, want %v", err, errNoSafeCell)
+	}
+
+	if game.HintsUsed != 0 {
+		t.Errorf("HintsUsed = %d, want 0", game.HintsUsed)
+	}
+}
+
+func TestRecordResult(t *testing.T) {
+	defer func(previous *Scoreboard) { scoreboard = previous }(scoreboard)
+	scoreboard = newScoreboard()
+
+	player := &tgbot.User{ID: 1, FirstName: "Alice"}
+	games := []struct {
+		rows    []string
+		taps    [][2]int
+		elapsed time.Duration
+	}{
+		{[]string{".*"}, [][2]int{{0, 0}}, 3 * time.Minute},
+		{[]string{".*"}, [][2]int{{0, 0}}, 2 * time.Minute},
+		{[]string{".*", ".."}, [][2]int{{1, 0}, {0, 1}}, time.Minute},
+	}
+
+	for _, scripted := range games {
+		game := &Game{Minefield: testMinefield(scripted.rows...)}
+		for _, tap := range scripted.taps {
+			game.Minefield.Open(tap[0], tap[1])
+		}
+
+		recordResult(game, player, scripted.elapsed)
+	}
+
+	score, ok := scoreboard.Get(player.ID)
+	if !ok {
+		t.Fatal("score not recorded")
+	}
+
+	if score.Wins != 2 || score.Losses != 1 || score.Games() != 3 || score.WinRate() != 66 {
+		t.Errorf("wins %d, losses %d, games %d, win rate %d, want 2, 1, 3, 66",
+			score.Wins, score.Losses, score.Games(), score.WinRate())
+	}
+
+	difficulty := Difficulty{Width: 2, Height: 1, Mines: 1}
+	if best := score.BestTimes[difficulty.String()]; best != 2*time.Minute {
+		t.Errorf("best time = %v, want %v", best, 2*time.Minute)
+	}
+}
+
+func TestPlayCallbackConcurrentTaps(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+	edits := withSendEdit(t)
+
+	game := &Game{Minefield: testMinefield("*.*", "...", "*.*"), ChatID: 10, MessageID: 100}
+	games.Set(game)
+	player := &tgbot.User{ID: 1}
+
+	taps := []CellCallbackData{{Row: 0, Col: 1}, {Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 1, Col: 1}}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, tap := range taps {
+			wg.Add(1)
+			go func(tap CellCallbackData) {
+				defer wg.Done()
+				playCallback(nil, game, player, tap)
+			}(tap)
+		}
+	}
+
+	wg.Wait()
+
+	if *edits != 3 {
+		t.Errorf("board edited %d times, want 3", *edits)
+	}
+
+	if opened := game.Minefield.countState(StateOpened); opened != 3 {
+		t.Errorf("%d cells opened, want 3", opened)
+	}
+}
+
+func TestGameLimit(t *testing.T) {
+	withConfig(t, BotConfig{MaxGames: 3})
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+
+	owner, other := &tgbot.User{ID: 1}, &tgbot.User{ID: 2}
+	for i := 1; i <= botConfig.MaxGames; i++ {
+		if _, reached := gameLimitText(owner); reached {
+			t.Fatalf("limit reached with %d games", i-1)
+		}
+
+		games.Set(testGame(owner.ID, 10, i))
+	}
+
+	if _, reached := gameLimitText(owner); !reached {
+		t.Errorf("game %d is allowed, want refused", botConfig.MaxGames+1)
+	}
+
+	if _, reached := gameLimitText(other); reached {
+		t.Error("limit of another user is reached")
+	}
+
+	withConfig(t, BotConfig{})
+	if _, reached := gameLimitText(owner); reached {
+		t.Error("limit is reached when max_games isn't set")
+	}
+}
+
+func TestPlayCallbackOutOfBounds(t *testing.T) {
+	edits := withSendEdit(t)
+	positions := []CellCallbackData{{Row: -1}, {Col: -1}, {Row: 3}, {Col: 3}, {Row: 999, Col: 999}}
+	for _, flagMode := range []bool{false, true} {
+		for _, position := range positions {
+			game := &Game{Minefield: testMinefield("*.*", "...", "*.*"), FlagMode: flagMode}
+			game.Minefield.Open(1, 1)
+			before := fieldStates(game.Minefield)
+
+			if answer := playCallback(nil, game, &tgbot.User{ID: 1}, position); answer != nil {
+				t.Errorf("%v: answered %q", position, answer.Text)
+			}
+
+			if after := fieldStates(game.Minefield); !slices.Equal(after, before) || game.Minefield.State != GameRunning {
+				t.Errorf("%v: field changed to %v with state %d", position, after, game.Minefield.State)
+			}
+		}
+	}
+
+	if *edits != 0 {
+		t.Errorf("board edited %d times, want 0", *edits)
+	}
+}
+
+func TestNoOpTapKeepsBoard(t *testing.T) {
+	withConfig(t, BotConfig{})
+	edits := withSendEdit(t)
+	game := &Game{Minefield: testMinefield("*.*", "...", "*.*")}
+	player := &tgbot.User{ID: 1}
+	game.Minefield.Open(1, 1)
+
+	signature := func() string {
+		return boardSignature(renderText(game, "title"), renderMinefield(game))
+	}
+
+	before := signature()
+	playCallback(nil, game, player, CellCallbackData{Row: 1, Col: 1})
+	if *edits != 0 {
+		t.Fatalf("tap on opened number without flags edited the board %d times", *edits)
+	}
+
+	if after := signature(); after != before {
+		t.Error("signature of unchanged board differs, it would be edited")
+	}
+
+	game.Minefield.Flag(0, 0)
+	if signature() == before {
+		t.Error("signature of flagged board is the same, it wouldn't be edited")
+	}
+}
+
+func TestPollWithBackoff(t *testing.T) {
+	slept := withRetrySleep(t)
+	failures := 5
+	calls := 0
+	poll := func() error {
+		calls++
+		if calls <= failures {
+			return errors.New("Bad Gateway")
+		}
+
+		return nil
+	}
+
+	if err := pollWithBackoff(poll, 4*time.Second); err != nil {
+		t.Fatalf("pollWithBackoff() = %v, want nil", err)
+	}
+
+	if calls != failures+1 {
+		t.Errorf("poll called %d times, want %d", calls, failures+1)
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second, 4 * time.Second}
+	if !slices.Equal(*slept, want) {
+		t.Errorf("backoffs %v, want %v", *slept, want)
+	}
+}
+
+func TestPlayCallbackFinishedGame(t *testing.T) {
+	edits := withSendEdit(t)
+	game := &Game{Minefield: testMinefield(".*")}
+	game.Minefield.Open(0, 0)
+	if game.Minefield.State != GameWin {
+		t.Fatalf("state = %d, want win", game.Minefield.State)
+	}
+
+	answer := playCallback(nil, game, &tgbot.User{ID: 2}, CellCallbackData{Row: 0, Col: 1})
+	if want := tr(defaultLanguage, "game.already_won"); answer == nil || answer.Text != want {
+		t.Errorf("answer = %+v, want %q", answer, want)
+	}
+
+	if game.Minefield.State != GameWin || *edits != 0 {
+		t.Errorf("late tap changed won board to state %d with %d edits", game.Minefield.State, *edits)
+	}
+}
+
+func TestFinishedBoard(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+
+	game := testGame(1, 10, 100)
+	game.Minefield = testMinefield(".*")
+	game.Minefield.Open(0, 0)
+	games.Set(game)
+	games.Finish(game)
+
+	tap := func(message *tgbot.Message) tbf.CallbackQueryRequest {
+		return tbf.CallbackQueryRequest{CallbackQuery: &tgbot.CallbackQuery{
+			From:    &tgbot.User{ID: 2},
+			Message: message,
+			Data:    "0,1",
+		}}
+	}
+
+	finished, ok := finishedBoard(tap(&tgbot.Message{MessageID: 100, Chat: &tgbot.Chat{ID: 10}}))
+	if !ok || finished != game || finished.Minefield.State != GameWin {
+		t.Errorf("finishedBoard() of won board = %v, %v, want the won game", finished, ok)
+	}
+
+	if _, ok := games.Get(game.Key()); ok {
+		t.Error("won game is still active")
+	}
+
+	for _, message := range []*tgbot.Message{
+		{MessageID: 101, Chat: &tgbot.Chat{ID: 10}},
+		{MessageID: 100, Chat: &tgbot.Chat{ID: 11}},
+		nil,
+	} {
+		if _, ok := finishedBoard(tap(message)); ok {
+			t.Errorf("finishedBoard() of message %+v found finished game", message)
+		}
+	}
+}
+
+func TestFinishedInlineBoard(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+
+	game := &Game{Minefield: testMinefield(".*"), InlineMessageID: "abc", CreatedAt: time.Now()}
+	game.Minefield.Open(0, 0)
+	games.Set(game)
+	games.Finish(game)
+
+	tap := func(inlineMessageID string) tbf.CallbackQueryRequest {
+		return tbf.CallbackQueryRequest{CallbackQuery: &tgbot.CallbackQuery{
+			From:            &tgbot.User{ID: 2},
+			InlineMessageID: inlineMessageID,
+			Data:            "0,1",
+		}}
+	}
+
+	if finished, ok := finishedBoard(tap("abc")); !ok || finished != game {
+		t.Errorf("finishedBoard() of won inline board = %v, %v, want the won game", finished, ok)
+	}
+
+	if _, ok := finishedBoard(tap("def")); ok {
+		t.Error("finishedBoard() of another inline board found finished game")
+	}
+
+	games.Evict(time.Now().Add(time.Minute))
+	if _, ok := finishedBoard(tap("abc")); ok {
+		t.Error("finishedBoard() found evicted inline game")
+	}
+}
+
+func TestCommandGame(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+
+	first, latest, other := testGame(1, 10, 100), testGame(1, 10, 101), testGame(1, 11, 102)
+	for _, game := range []*Game{first, latest, other} {
+		games.Set(game)
+	}
+
+	tests := []struct {
+		name    string
+		chatID  int
+		replyTo int
+		want    *Game
+	}{
+		{"reply to older board", 10, 100, first},
+		{"reply to latest board", 10, 101, latest},
+		{"no reply", 10, 0, latest},
+		{"reply to other message", 10, 50, latest},
+		{"reply to board of other chat", 10, 102, latest},
+		{"chat without games", 12, 0, nil},
+	}
+
+	for _, test := range tests {
+		req := testRequest(1, "/hint")
+		req.Message.Chat.ID = test.chatID
+		if test.replyTo != 0 {
+			req.Message.ReplyToMessage = &tgbot.Message{MessageID: test.replyTo, Chat: req.Message.Chat}
+		}
+
+		game, ok := commandGame(req)
+		if game != test.want || ok != (test.want != nil) {
+			t.Errorf("%s: commandGame() = %v, %v", test.name, game, ok)
+		}
+	}
+}
+
+func TestToasts(t *testing.T) {
+	withConfig(t, BotConfig{})
+	player := &tgbot.User{ID: 1}
+	lang := userLanguage(player)
+
+	flagTests := []struct {
+		state int
+		want  string
+	}{
+		{StateFlagged, tr(lang, "toast.flagged", 3, 5)},
+		{StateQuestion, tr(lang, "toast.question", 3, 5)},
+		{StateClosed, tr(lang, "toast.unflagged", 3, 5)},
+		{StateOpened, tr(lang, "toast.nothing")},
+	}
+
+	for _, test := range flagTests {
+		if got := flagToast(lang, test.state, 2, 4); got != test.want {
+			t.Errorf("flagToast() of state %d = %q, want %q", test.state, got, test.want)
+		}
+	}
+
+	defer func(previous *GameStore) { games = previous }(games)
+	games = newGameStore()
+
+	game := &Game{Minefield: testMinefield("..*", "...", "...", "..*")}
+	games.Set(game)
+	// opened corner keeps the first tap from moving mines away
+	game.Minefield.Field[3][0].State = StateOpened
+	game.Minefield.Flag(0, 2)
+	tapTests := []struct {
+		name     string
+		tap      Position
+		want     string
+		wantMove bool
+	}{
+		{"opened", Position{0, 1}, tr(lang, "toast.opened", 1, 2), true},
+		{"blocked", Position{0, 2}, tr(lang, "toast.blocked", 1, 3), false},
+		{"chorded", Position{0, 1}, tr(lang, "toast.chorded", 1, 2, 7), true},
+	}
+
+	edits := withSendEdit(t)
+	for _, test := range tapTests {
+		before := *edits
+		answer := playCallback(nil, game, player, CellCallbackData{Row: test.tap.Row, Col: test.tap.Col})
+		if answer == nil || answer.Text != test.want {
+			t.Errorf("%s: answer = %+v, want %q", test.name, answer, test.want)
+		}
+
+		if moved := *edits > before; moved != test.wantMove {
+			t.Errorf("%s: board edited %v, want %v", test.name, moved, test.wantMove)
+		}
+	}
+}
+
+func TestOpenCommandMatchesTap(t *testing.T) {
+	withConfig(t, BotConfig{})
+	player := &tgbot.User{ID: 1}
+	rows := []string{"..*..", "..*..", ".....", "*...*"}
+	typed, tapped := &Game{Minefield: testMinefield(rows...)}, &Game{Minefield: testMinefield(rows...)}
+
+	for _, text := range []string{"/open 1 4", "/open 1 1", "/open 3 3"} {
+		row, col, ok := parseOpenArgs(text)
+		if !ok {
+			t.Fatalf("parseOpenArgs(%q) failed", text)
+		}
+
+		data, err := decodeCallbackData(encodeCallbackData(CellCallbackData{Row: row, Col: col}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		typedText, _, typedChanged := playCell(typed, player, row, col)
+		tappedText, _, tappedChanged := playCell(tapped, player, data.Row, data.Col)
+		if typedText != tappedText || typedChanged != tappedChanged {
+			t.Errorf("%q: command gave %q, %v, tap gave %q, %v", text, typedText, typedChanged, tappedText, tappedChanged)
+		}
+
+		if !slices.Equal(fieldStates(typed.Minefield), fieldStates(tapped.Minefield)) || typed.Minefield.State != tapped.Minefield.State {
+			t.Errorf("%q: boards differ after command and tap", text)
+		}
+	}
+
+	if typed.Minefield.Field[0][3].State != StateOpened {
+		t.Error("/open 1 4 didn't open cell 0,3")
+	}
+
+	for _, text := range []string{"/open", "/open 1", "/open a 2", "/open 1 2 3"} {
+		if _, _, ok := parseOpenArgs(text); ok {
+			t.Errorf("parseOpenArgs(%q) accepted invalid arguments", text)
+		}
+	}
+}
+
+func TestChatBusyGame(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	defer func(previous *ChatSettingsStore) { chatSettings = previous }(chatSettings)
+	games = newGameStore()
+
+	active := testGame(1, 10, 100)
+	games.Set(active)
+
+	tests := []struct {
+		name       string
+		singleGame bool
+		chatSingle bool
+		chatID     int
+		want       bool
+	}{
+		{"allowed", false, false, 10, false},
+		{"bot config", true, false, 10, true},
+		{"chat settings", false, true, 10, true},
+		{"other chat", true, false, 11, false},
+	}
+
+	for _, test := range tests {
+		withConfig(t, BotConfig{SingleGame: test.singleGame})
+		chatSettings = newChatSettingsStore()
+		chatSettings.Update("", test.chatID, func(settings *ChatSettings) { settings.SingleGame = test.chatSingle })
+
+		game, busy := chatBusyGame("", test.chatID)
+		if busy != test.want || busy && game != active {
+			t.Errorf("%s: chatBusyGame() = %v, %v, want busy %v", test.name, game, busy, test.want)
+		}
+	}
+}
+
+func TestSuggestionsAreAccepted(t *testing.T) {
+	withConfig(t, BotConfig{MaxDensity: defaultMaxDensity})
+	width, height := int64(8), int64(6)
+	tests := []struct {
+		name        string
+		suggestions []int64
+		validate    func(int64, error) error
+	}{
+		{"width", sizeSuggestions(), func(width int64, err error) error {
+			return validateWidth(width, err)
+		}},
+		{"height", sizeSuggestions(), func(height int64, err error) error {
+			if err = validateHeight(height, err); err != nil {
+				return err
+			}
+
+			return validateKeyboard(width, height)
+		}},
+		{"mines", minesSuggestions(width, height), func(mines int64, err error) error {
+			return validateMines(width, height, mines, err)
+		}},
+	}
+
+	for _, test := range tests {
+		var tapped []int64
+		for _, row := range suggestionButtons(test.suggestions) {
+			if len(row) > maxKeyboardWidth {
+				t.Errorf("%s: row of %d buttons, want at most %d", test.name, len(row), maxKeyboardWidth)
+			}
+
+			for _, button := range row {
+				value, err := readValue(answers(button.Text), nil, "", test.suggestions, test.validate)
+				if err != nil {
+					t.Errorf("%s: suggestion %q rejected: %v", test.name, button.Text, err)
+				}
+
+				tapped = append(tapped, value)
+			}
+		}
+
+		if len(tapped) == 0 || !slices.Equal(tapped, test.suggestions) {
+			t.Errorf("%s: buttons send %v, want %v", test.name, tapped, test.suggestions)
+		}
+	}
+
+	if _, err := readNumber(answers("/cancel"), "", nil); err != errGameCancelled {
+		t.Errorf("readNumber() of /cancel = %v, want %v", err, errGameCancelled)
+	}
+}
+
+func TestReadValueRetries(t *testing.T) {
+	withConfig(t, BotConfig{})
+	validate := func(width int64, err error) error {
+		return validateWidth(width, err)
+	}
+
+	tests := []struct {
+		name        string
+		answers     []string
+		want        int64
+		wantErr     bool
+		wantRetries []int
+	}{
+		{"valid", []string{"5"}, 5, false, nil},
+		{"invalid then valid", []string{"five", "5"}, 5, false, []int{2}},
+		{"out of range then valid", []string{"100", "0", "6"}, 6, false, []int{2, 1}},
+		{"attempts run out", []string{"a", "b", "c"}, 0, true, []int{2, 1}},
+		{"cancelled", []string{"x", "/cancel"}, 0, true, []int{2}},
+	}
+
+	for _, test := range tests {
+		asked := 0
+		ask := func(string, []int64) string {
+			asked++
+			return test.answers[asked-1]
+		}
+
+		var retries []int
+		value, err := readValue(ask, func(err error, attemptsLeft int) {
+			retries = append(retries, attemptsLeft)
+		}, "", nil, validate)
+
+		if (err != nil) != test.wantErr || !test.wantErr && value != test.want {
+			t.Errorf("%s: readValue() = %d, %v, want %d", test.name, value, err, test.want)
+		}
+
+		if asked != len(test.answers) || !slices.Equal(retries, test.wantRetries) {
+			t.Errorf("%s: asked %d times with retries %v, want %d and %v",
+				test.name, asked, retries, len(test.answers), test.wantRetries)
+		}
+	}
+}
+
+func TestSignificantMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		cell    Cell
+		chorded bool
+		want    bool
+	}{
+		{"empty cell", Cell{Type: TypeEmpty}, false, false},
+		{"small number", Cell{Type: Type3}, false, false},
+		{"big number", Cell{Type: Type4}, false, true},
+		{"eight", Cell{Type: Type8}, false, true},
+		{"mine", Cell{Type: TypeMine}, false, false},
+		{"chord", Cell{Type: Type1}, true, true},
+	}
+
+	for _, test := range tests {
+		if got := significantMove(test.cell, test.chorded); got != test.want {
+			t.Errorf("%s: significantMove() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDropDeletedBoard(t *testing.T) {
+	defer func(previous *GameStore) { games = previous }(games)
+	withRetrySleep(t)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantDropped bool
+	}{
+		{"deleted board", errors.New("Bad Request: message to edit not found"), true},
+		{"other error", errors.New("Forbidden: bot was kicked from the group chat"), false},
+	}
+
+	for _, test := range tests {
+		games = newGameStore()
+		game := testGame(1, 10, 100)
+		games.Set(game)
+
+		calls := 0
+		err := retryEdit(func() error {
+			calls++
+			return test.err
+		})
+
+		if dropped := dropDeletedBoard(game, err); dropped != test.wantDropped {
+			t.Errorf("%s: dropDeletedBoard() = %v, want %v", test.name, dropped, test.wantDropped)
+		}
+
+		if _, ok := games.Get(game.Key()); ok == test.wantDropped {
+			t.Errorf("%s: game stored %v, want %v", test.name, ok, !test.wantDropped)
+		}
+
+		if calls != 1 {
+			t.Errorf("%s: edit called %d times, want 1", test.name, calls)
+		}
+	}
+}
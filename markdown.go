@@ -0,0 +1,19 @@
+package main
+
+import (
+	"strings"
+)
+
+// markdownEscaper escapes characters having special meaning in Markdown
+// parse mode used by QuickMessageMD, they're shown literally after escaping
+var markdownEscaper = strings.NewReplacer(
+	"_", "\\_",
+	"*", "\\*",
+	"`", "\\`",
+	"[", "\\[",
+)
+
+// escapeMarkdown escapes dynamic value interpolated into Markdown message
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
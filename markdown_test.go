@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Alice", "Alice"},
+		{"snake_case", "snake\\_case"},
+		{"*bold* `code`", "\\*bold\\* \\`code\\`"},
+		{"[link](url)", "\\[link](url)"},
+		{"Ёжик 🐱", "Ёжик 🐱"},
+	}
+
+	for _, test := range tests {
+		if got := escapeMarkdown(test.text); got != test.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsNamespace = "minesweeper"
+)
+
+var (
+	gamesCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "games_created_total",
+		Help:      "Count of created games.",
+	})
+
+	gamesWon = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "games_won_total",
+		Help:      "Count of won games.",
+	})
+
+	gamesLost = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "games_lost_total",
+		Help:      "Count of lost games.",
+	})
+
+	callbackQueries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "callback_queries_total",
+		Help:      "Count of handled callback queries.",
+	})
+
+	apiErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_errors_total",
+		Help:      "Count of failed Telegram API requests.",
+	})
+
+	handlerPanics = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "handler_panics_total",
+		Help:      "Count of panics recovered in update handlers.",
+	})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "active_games",
+		Help:      "Count of games in progress.",
+	}, func() float64 {
+		return float64(games.Count())
+	})
+)
+
+// serveMetrics serves Prometheus metrics on /metrics path of the address
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	slog.Info("Serving metrics", "addr", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		slog.Error("Unable to serve metrics", "error", err)
+	}
+}
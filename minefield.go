@@ -0,0 +1,511 @@
+package main
+
+import (
+	"log/slog"
+	"math/rand"
+)
+
+// maxRevealAttempts limits count of layouts generated to reach MinReveal
+const maxRevealAttempts = 100
+
+// Cell types
+const (
+	TypeEmpty = iota
+	Type1
+	Type2
+	Type3
+	Type4
+	Type5
+	Type6
+	Type7
+	Type8
+	TypeMine
+)
+
+// Cell states
+const (
+	StateClosed = iota
+	StateOpened
+	StateFlagged
+	StateQuestion
+)
+
+// Game states
+const (
+	GameRunning = iota
+	GameWin
+	GameLose
+)
+
+// Cell contains type and state of a single minefield cell
+type Cell struct {
+	Type  int `json:"type"`
+	State int `json:"state"`
+}
+
+// closed checks if cell can be opened, question marks don't prevent opening
+func (c Cell) closed() bool {
+	return c.State == StateClosed || c.State == StateQuestion
+}
+
+// Minefield contains mines layout and progress of a single game
+type Minefield struct {
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Mines  int      `json:"mines"`
+	Seed   int64    `json:"seed"`
+	State  int      `json:"state"`
+	Field  [][]Cell `json:"field"`
+
+	// Manual disables opening of cells around empty ones
+	Manual bool `json:"manual,omitempty"`
+
+	// FloodLimit caps count of cells opened by a single tap when it's positive,
+	// the rest is opened by tapping opened empty cells on the frontier
+	FloodLimit int `json:"flood_limit,omitempty"`
+
+	// MinReveal is count of cells the first tap should open when it's positive,
+	// mines layout is regenerated from consecutive seeds until it does
+	MinReveal int `json:"min_reveal,omitempty"`
+
+	// Cleared is set once mines are moved away from the first opened cell,
+	// the layout is kept when that move is undone
+	Cleared bool `json:"cleared,omitempty"`
+
+	// History contains indexes of cells opened by each move,
+	// used to undo the last one
+	History [][]int `json:"history,omitempty"`
+
+	// Moves contains tapped cells of moves in History, used to share the game
+	Moves []Move `json:"moves,omitempty"`
+}
+
+// Move contains index of tapped cell and indexes of neighbors opened by
+// chording it, neighbors are empty when the cell itself was opened.
+// Flags contains flagged cells which stopped flood of the move and
+// Questions contains question marked cells it opened
+type Move struct {
+	Index     int   `json:"index"`
+	Chord     []int `json:"chord,omitempty"`
+	Flags     []int `json:"flags,omitempty"`
+	Questions []int `json:"questions,omitempty"`
+}
+
+// newMinefield creates minefield with mines layout generated from the seed,
+// same seed and dimensions always produce same layout
+func newMinefield(width, height, mines int, seed int64) *Minefield {
+	minefield := &Minefield{
+		Width:  width,
+		Height: height,
+		Mines:  mines,
+		Seed:   seed,
+		State:  GameRunning,
+		Field:  make([][]Cell, height),
+	}
+
+	for row := range minefield.Field {
+		minefield.Field[row] = make([]Cell, width)
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	for _, index := range random.Perm(width * height)[:mines] {
+		minefield.Field[index/width][index%width].Type = TypeMine
+	}
+
+	minefield.countNeighbors()
+	return minefield
+}
+
+// Open opens closed cell and all empty cells around it and returns indexes
+// of opened cells, first opened cell in the game is guaranteed to be safe
+func (m *Minefield) Open(row, col int) []int {
+	return m.record(Move{Index: row*m.Width + col}, func() {
+		m.open(row, col)
+	})
+}
+
+func (m *Minefield) open(row, col int) {
+	if m.State != GameRunning || !m.contains(row, col) || !m.Field[row][col].closed() {
+		return
+	}
+
+	// mines are moved only by the tap which opens the first cell, so the
+	// layout depends only on seed and moves recorded in history
+	if !m.Cleared && m.countState(StateOpened) == 0 {
+		m.clearArea(row, col)
+		if m.MinReveal > 0 && !m.Manual {
+			m.ensureReveal(row, col)
+		}
+
+		m.Cleared = true
+	}
+
+	m.flood([]int{row*m.Width + col})
+}
+
+// flood opens the cells and empty areas around them breadth-first, so flood
+// limited by FloodLimit reveals area closest to the cells. The cells themselves
+// are always opened
+func (m *Minefield) flood(cells []int) {
+	queue := cells
+	for i, opened := 0, 0; i < len(queue); i++ {
+		if i >= len(cells) && m.FloodLimit > 0 && opened >= m.FloodLimit {
+			break
+		}
+
+		row, col := queue[i]/m.Width, queue[i]%m.Width
+		cell := &m.Field[row][col]
+		if !cell.closed() {
+			continue
+		}
+
+		cell.State = StateOpened
+		opened++
+		if cell.Type == TypeMine {
+			m.State = GameLose
+			return
+		}
+
+		if cell.Type == TypeEmpty && !m.Manual {
+			m.eachNeighbor(row, col, func(r, c int) {
+				queue = append(queue, r*m.Width+c)
+			})
+		}
+	}
+
+	if m.countState(StateOpened) == m.Width*m.Height-m.Mines {
+		m.State = GameWin
+	}
+}
+
+// Chord opens all not flagged neighbors of opened number cell when count
+// of flags around it matches the number and returns indexes of opened cells,
+// opened empty cells left on the frontier by limited flood are chorded as zeros
+func (m *Minefield) Chord(row, col int) []int {
+	if m.State != GameRunning || !m.contains(row, col) {
+		return nil
+	}
+
+	cell := m.Field[row][col]
+	if cell.State != StateOpened || cell.Type == TypeMine {
+		return nil
+	}
+
+	flags := 0
+	m.eachNeighbor(row, col, func(r, c int) {
+		if m.Field[r][c].State == StateFlagged {
+			flags++
+		}
+	})
+
+	if flags != cell.Type-TypeEmpty {
+		return nil
+	}
+
+	var cells []int
+	m.eachNeighbor(row, col, func(r, c int) {
+		if m.Field[r][c].closed() {
+			cells = append(cells, r*m.Width+c)
+		}
+	})
+
+	return m.record(Move{Index: row*m.Width + col, Chord: cells}, func() {
+		m.flood(cells)
+	})
+}
+
+// Undo closes cells opened by the last move, moves that
+// lost the game can't be undone
+func (m *Minefield) Undo() bool {
+	if m.State != GameRunning || len(m.History) == 0 {
+		return false
+	}
+
+	last := m.History[len(m.History)-1]
+	var questions []int
+	if len(m.Moves) == len(m.History) {
+		questions = m.Moves[len(m.Moves)-1].Questions
+	}
+
+	m.History = m.History[:len(m.History)-1]
+	if len(m.Moves) > len(m.History) {
+		m.Moves = m.Moves[:len(m.History)]
+	}
+
+	for _, index := range last {
+		m.Field[index/m.Width][index%m.Width].State = StateClosed
+	}
+
+	for _, index := range questions {
+		m.Field[index/m.Width][index%m.Width].State = StateQuestion
+	}
+
+	return true
+}
+
+// GiveUp ends running game as lost without opening any cell
+func (m *Minefield) GiveUp() bool {
+	if m.State != GameRunning {
+		return false
+	}
+
+	m.State = GameLose
+	return true
+}
+
+// SafeCell returns random closed cell without mine, cells next to
+// already opened ones are preferred
+func (m *Minefield) SafeCell() (int, int, bool) {
+	var safe, frontier []int
+	for index := 0; index < m.Width*m.Height; index++ {
+		row, col := index/m.Width, index%m.Width
+		cell := m.Field[row][col]
+		if !cell.closed() || cell.Type == TypeMine {
+			continue
+		}
+
+		safe = append(safe, index)
+		m.eachNeighbor(row, col, func(r, c int) {
+			if m.Field[r][c].State == StateOpened {
+				frontier = append(frontier, index)
+			}
+		})
+	}
+
+	if len(frontier) > 0 {
+		safe = frontier
+	}
+
+	if len(safe) == 0 {
+		return 0, 0, false
+	}
+
+	index := safe[rand.Intn(len(safe))]
+	return index / m.Width, index % m.Width, true
+}
+
+// Settled checks if cell is opened and has no closed neighbors,
+// so neither opening nor chording it can change anything
+func (m *Minefield) Settled(row, col int) bool {
+	if !m.contains(row, col) || m.Field[row][col].State != StateOpened {
+		return false
+	}
+
+	settled := true
+	m.eachNeighbor(row, col, func(r, c int) {
+		if m.Field[r][c].closed() {
+			settled = false
+		}
+	})
+
+	return settled
+}
+
+// Flag cycles mark of closed cell from flag to question mark and back to none
+func (m *Minefield) Flag(row, col int) {
+	if m.State != GameRunning || !m.contains(row, col) {
+		return
+	}
+
+	cell := &m.Field[row][col]
+	switch cell.State {
+	case StateClosed:
+		cell.State = StateFlagged
+	case StateFlagged:
+		cell.State = StateQuestion
+	case StateQuestion:
+		cell.State = StateClosed
+	}
+}
+
+// clearArea moves mines from the cell and its neighbors to random free cells,
+// only the cell itself is cleared when there is not enough free space
+func (m *Minefield) clearArea(row, col int) {
+	area := map[int]bool{row*m.Width + col: true}
+	m.eachNeighbor(row, col, func(r, c int) {
+		area[r*m.Width+c] = true
+	})
+
+	if m.Width*m.Height-len(area) < m.Mines {
+		area = map[int]bool{row*m.Width + col: true}
+	}
+
+	var free []int
+	moved := 0
+	for index := 0; index < m.Width*m.Height; index++ {
+		cell := &m.Field[index/m.Width][index%m.Width]
+		if area[index] {
+			if cell.Type == TypeMine {
+				cell.Type = TypeEmpty
+				moved++
+			}
+		} else if cell.Type != TypeMine {
+			free = append(free, index)
+		}
+	}
+
+	if moved == 0 {
+		return
+	}
+
+	random := rand.New(rand.NewSource(m.Seed + int64(row*m.Width+col)))
+	random.Shuffle(len(free), func(i, j int) {
+		free[i], free[j] = free[j], free[i]
+	})
+
+	for _, index := range free[:moved] {
+		m.Field[index/m.Width][index%m.Width].Type = TypeMine
+	}
+
+	m.countNeighbors()
+}
+
+// ensureReveal replaces mines layout with one where the cell opens at least
+// MinReveal cells, layouts are generated from seeds following the minefield's
+// one and the largest reveal is used when none of them is big enough
+func (m *Minefield) ensureReveal(row, col int) {
+	best, bestSize := m, m.revealSize(row, col)
+	for attempt := int64(1); attempt <= maxRevealAttempts && bestSize < m.MinReveal; attempt++ {
+		candidate := newMinefield(m.Width, m.Height, m.Mines, m.Seed+attempt)
+		candidate.clearArea(row, col)
+		for r := range candidate.Field {
+			for c := range candidate.Field[r] {
+				candidate.Field[r][c].State = m.Field[r][c].State
+			}
+		}
+
+		if size := candidate.revealSize(row, col); size > bestSize {
+			best, bestSize = candidate, size
+		}
+	}
+
+	if bestSize < m.MinReveal {
+		slog.Debug("Minimal first reveal not reached", "seed", m.Seed, "min_reveal", m.MinReveal, "size", bestSize)
+	}
+
+	for r := range m.Field {
+		for c := range m.Field[r] {
+			m.Field[r][c].Type = best.Field[r][c].Type
+		}
+	}
+}
+
+// revealSize returns count of cells opening the cell would open without flood limit
+func (m *Minefield) revealSize(row, col int) int {
+	clone := m.clone()
+	clone.FloodLimit = 0
+	clone.flood([]int{row*m.Width + col})
+	return clone.countState(StateOpened) - m.countState(StateOpened)
+}
+
+// Difficulty returns dimensions and mines count of the minefield
+func (m *Minefield) Difficulty() Difficulty {
+	return Difficulty{
+		Width:  m.Width,
+		Height: m.Height,
+		Mines:  m.Mines,
+	}
+}
+
+func newSeed() int64 {
+	return rand.Int63()
+}
+
+func (m *Minefield) contains(row, col int) bool {
+	return row >= 0 && row < m.Height && col >= 0 && col < m.Width
+}
+
+func (m *Minefield) eachNeighbor(row, col int, fn func(row, col int)) {
+	for r := row - 1; r <= row+1; r++ {
+		for c := col - 1; c <= col+1; c++ {
+			if (r != row || c != col) && m.contains(r, c) {
+				fn(r, c)
+			}
+		}
+	}
+}
+
+// record saves cells opened by the move and its tap to the history and returns them
+func (m *Minefield) record(tap Move, move func()) []int {
+	states := make([]int, m.Width*m.Height)
+	for index := range states {
+		states[index] = m.Field[index/m.Width][index%m.Width].State
+	}
+
+	move()
+
+	var opened []int
+	for index, state := range states {
+		if state != StateOpened && m.Field[index/m.Width][index%m.Width].State == StateOpened {
+			opened = append(opened, index)
+			if state == StateQuestion {
+				tap.Questions = append(tap.Questions, index)
+			}
+		}
+	}
+
+	if len(opened) > 0 {
+		tap.Flags = m.floodFlags(opened)
+		m.History = append(m.History, opened)
+		m.Moves = append(m.Moves, tap)
+	}
+
+	return opened
+}
+
+// floodFlags returns flagged neighbors of opened empty cells
+func (m *Minefield) floodFlags(opened []int) []int {
+	if m.Manual {
+		return nil
+	}
+
+	seen := map[int]bool{}
+	var flags []int
+	for _, index := range opened {
+		row, col := index/m.Width, index%m.Width
+		if m.Field[row][col].Type != TypeEmpty {
+			continue
+		}
+
+		m.eachNeighbor(row, col, func(r, c int) {
+			if flag := r*m.Width + c; m.Field[r][c].State == StateFlagged && !seen[flag] {
+				seen[flag] = true
+				flags = append(flags, flag)
+			}
+		})
+	}
+
+	return flags
+}
+
+func (m *Minefield) countNeighbors() {
+	for row := range m.Field {
+		for col := range m.Field[row] {
+			if m.Field[row][col].Type == TypeMine {
+				continue
+			}
+
+			mines := 0
+			m.eachNeighbor(row, col, func(r, c int) {
+				if m.Field[r][c].Type == TypeMine {
+					mines++
+				}
+			})
+
+			m.Field[row][col].Type = TypeEmpty + mines
+		}
+	}
+}
+
+func (m *Minefield) countState(state int) int {
+	count := 0
+	for _, cells := range m.Field {
+		for _, cell := range cells {
+			if cell.State == state {
+				count++
+			}
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// testMinefield creates minefield from rows where "*" is a mine, mines aren't
+// moved by the first tap so cells are opened exactly as drawn
+func testMinefield(rows ...string) *Minefield {
+	minefield := &Minefield{
+		Width:   len(rows[0]),
+		Height:  len(rows),
+		State:   GameRunning,
+		Cleared: true,
+		Field:   make([][]Cell, len(rows)),
+	}
+
+	for row, text := range rows {
+		minefield.Field[row] = make([]Cell, len(text))
+		for col, char := range text {
+			if char == '*' {
+				minefield.Field[row][col].Type = TypeMine
+				minefield.Mines++
+			}
+		}
+	}
+
+	minefield.countNeighbors()
+	return minefield
+}
+
+func TestMinefieldFirstOpenIsSafe(t *testing.T) {
+	boards := []struct {
+		width, height, mines int
+		clearsNeighbors      bool
+	}{
+		{8, 8, 10, true},
+		{8, 8, 50, true},
+		{4, 4, 15, false},
+	}
+
+	for _, board := range boards {
+		for seed := int64(1); seed <= 100; seed++ {
+			for _, tap := range [][2]int{{0, 0}, {board.height / 2, board.width / 2}} {
+				minefield := newMinefield(board.width, board.height, board.mines, seed)
+				minefield.Open(tap[0], tap[1])
+				if minefield.Field[tap[0]][tap[1]].Type == TypeMine || minefield.State == GameLose {
+					t.Fatalf("%dx%d/%d seed %d: first open of %v hit a mine", board.width, board.height, board.mines, seed, tap)
+				}
+
+				if !board.clearsNeighbors {
+					continue
+				}
+
+				minefield.eachNeighbor(tap[0], tap[1], func(row, col int) {
+					if minefield.Field[row][col].Type == TypeMine {
+						t.Fatalf("%dx%d/%d seed %d: neighbor %d,%d of first open %v is a mine", board.width, board.height, board.mines, seed, row, col, tap)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestMinefieldManualMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		manual bool
+		want   []int
+	}{
+		{"flood", false, []int{0, 1, 3, 4, 6, 7}},
+		{"manual", true, []int{0}},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield("..*", "..*", "..*")
+		minefield.Manual = test.manual
+		opened := minefield.Open(0, 0)
+		slices.Sort(opened)
+		if !slices.Equal(opened, test.want) {
+			t.Errorf("%s: opened %v, want %v", test.name, opened, test.want)
+		}
+	}
+}
+
+func TestMinefieldFloodLimit(t *testing.T) {
+	rows := []string{"......", "......", "......", "......", "......", ".....*"}
+	for _, limit := range []int{1, 3, 5, 10} {
+		minefield := testMinefield(rows...)
+		minefield.FloodLimit = limit
+		if opened := minefield.Open(0, 0); len(opened) != limit {
+			t.Errorf("limit %d: first tap opened %d cells", limit, len(opened))
+		}
+
+		// continue by tapping opened empty cells on the frontier
+		for steps := 0; minefield.State == GameRunning; steps++ {
+			if steps > 35 {
+				t.Fatalf("limit %d: game isn't finished after %d steps", limit, steps)
+			}
+
+			for index := 0; index < 36; index++ {
+				row, col := index/6, index%6
+				if minefield.Field[row][col].State != StateOpened {
+					continue
+				}
+
+				// neighbors of the tapped cell are always opened, the limit
+				// applies to flood beyond them
+				if opened := minefield.Chord(row, col); len(opened) > 8+limit {
+					t.Errorf("limit %d: step opened %d cells", limit, len(opened))
+				} else if len(opened) > 0 {
+					break
+				}
+			}
+		}
+
+		if minefield.State != GameWin {
+			t.Errorf("limit %d: state = %d, want win", limit, minefield.State)
+		}
+	}
+}
+
+func TestMinefieldMinReveal(t *testing.T) {
+	tests := []struct {
+		name      string
+		minReveal int
+		wantMin   int
+	}{
+		{"reachable", 20, 20},
+		{"unreachable", 1000, 1},
+	}
+
+	for _, test := range tests {
+		for seed := int64(1); seed <= 20; seed++ {
+			minefield := newMinefield(9, 9, 20, seed)
+			minefield.MinReveal = test.minReveal
+			opened := minefield.Open(4, 4)
+			if len(opened) < test.wantMin || minefield.State == GameLose {
+				t.Errorf("%s, seed %d: first tap opened %d cells with state %d", test.name, seed, len(opened), minefield.State)
+			}
+
+			mines := 0
+			for _, row := range minefield.Field {
+				for _, cell := range row {
+					if cell.Type == TypeMine {
+						mines++
+					}
+				}
+			}
+
+			if mines != minefield.Mines {
+				t.Errorf("%s, seed %d: %d mines on the board, want %d", test.name, seed, mines, minefield.Mines)
+			}
+
+			again := newMinefield(9, 9, 20, seed)
+			again.MinReveal = test.minReveal
+			again.Open(4, 4)
+			if !sameBoard(minefield, again) {
+				t.Errorf("%s, seed %d: layout isn't reproducible", test.name, seed)
+			}
+		}
+	}
+}
+
+func TestMinefieldChord(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      [][2]int
+		wantOpened int
+		wantState  int
+	}{
+		{"correct flag", [][2]int{{0, 0}}, 7, GameWin},
+		{"wrong flag", [][2]int{{0, 1}}, 1, GameLose},
+		{"missing flag", nil, 0, GameRunning},
+		{"extra flag", [][2]int{{0, 0}, {0, 1}}, 0, GameRunning},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield("*..", "...", "...")
+		minefield.Open(1, 1)
+		for _, flag := range test.flags {
+			minefield.Flag(flag[0], flag[1])
+		}
+
+		opened := minefield.countState(StateOpened)
+		minefield.Chord(1, 1)
+		if got := minefield.countState(StateOpened) - opened; got != test.wantOpened {
+			t.Errorf("%s: chord opened %d cells, want %d", test.name, got, test.wantOpened)
+		}
+
+		if minefield.State != test.wantState {
+			t.Errorf("%s: state = %d, want %d", test.name, minefield.State, test.wantState)
+		}
+	}
+}
+
+func TestMinefieldFlagCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		state int
+		want  int
+	}{
+		{"closed", StateClosed, StateFlagged},
+		{"flagged", StateFlagged, StateQuestion},
+		{"question mark", StateQuestion, StateClosed},
+		{"opened", StateOpened, StateOpened},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield("..*", "...", "...")
+		minefield.Field[0][0].State = test.state
+		minefield.Flag(0, 0)
+		if state := minefield.Field[0][0].State; state != test.want {
+			t.Errorf("%s: state after Flag() = %d, want %d", test.name, state, test.want)
+		}
+	}
+
+	minefield := testMinefield("..*", "...", "...")
+	minefield.Flag(1, 1)
+	minefield.Flag(1, 1)
+	minefield.Open(1, 1)
+	if state := minefield.Field[1][1].State; state != StateOpened {
+		t.Errorf("question mark cell has state %d after Open(), want opened", state)
+	}
+}
+
+func TestMinefieldUndoLastMove(t *testing.T) {
+	rows := []string{"..*...", "..*...", "..*..*"}
+	tests := []struct {
+		name       string
+		last       [2]int
+		wantUndo   bool
+		wantOpened int
+	}{
+		{"number", [2]int{0, 3}, true, 1},
+		{"flood", [2]int{0, 5}, true, 6},
+		{"mine", [2]int{0, 2}, false, 1},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield(rows...)
+		minefield.Open(0, 0)
+		before, opened := fieldStates(minefield), minefield.countState(StateOpened)
+		minefield.Open(test.last[0], test.last[1])
+		if opened := minefield.countState(StateOpened) - opened; opened != test.wantOpened {
+			t.Errorf("%s: last move opened %d cells, want %d", test.name, opened, test.wantOpened)
+		}
+
+		if undone := minefield.Undo(); undone != test.wantUndo {
+			t.Errorf("%s: Undo() = %v, want %v", test.name, undone, test.wantUndo)
+			continue
+		}
+
+		if !test.wantUndo {
+			continue
+		}
+
+		if after := fieldStates(minefield); !slices.Equal(after, before) {
+			t.Errorf("%s: states after undo %v, want %v", test.name, after, before)
+		}
+	}
+}
+
+func TestMinefieldUndo(t *testing.T) {
+	minefield := testMinefield("..*", "...", "...")
+	minefield.Flag(2, 2)
+	minefield.Flag(2, 2)
+	if state := minefield.Field[2][2].State; state != StateQuestion {
+		t.Fatalf("state after two flags = %d, want question mark", state)
+	}
+
+	minefield.Open(0, 1)
+	minefield.Open(2, 0)
+	if minefield.State != GameWin {
+		t.Fatalf("state = %d, want win", minefield.State)
+	}
+
+	minefield.State = GameRunning
+	if !minefield.Undo() {
+		t.Fatal("Undo() = false, want true")
+	}
+
+	if state := minefield.Field[2][2].State; state != StateQuestion {
+		t.Errorf("undone question mark has state %d", state)
+	}
+
+	if state := minefield.Field[0][1].State; state != StateOpened {
+		t.Errorf("cell of the first move has state %d, want opened", state)
+	}
+
+	if state := minefield.Field[1][1].State; state != StateClosed {
+		t.Errorf("undone cell has state %d, want closed", state)
+	}
+
+	if len(minefield.History) != 1 || len(minefield.Moves) != 1 {
+		t.Errorf("history has %d moves and %d taps, want 1", len(minefield.History), len(minefield.Moves))
+	}
+}
+
+func TestMinefieldUndoKeepsLayout(t *testing.T) {
+	minefield := newMinefield(8, 8, 10, 42)
+	minefield.Open(0, 0)
+	layout := mineLayout(minefield)
+	if !minefield.Undo() {
+		t.Fatal("Undo() = false, want true")
+	}
+
+	// tap on a mine would move it if the layout was cleared again
+	mine := slices.Index(layout, true)
+	minefield.Open(mine/minefield.Width, mine%minefield.Width)
+	if !slices.Equal(mineLayout(minefield), layout) {
+		t.Error("mines were moved again after the first move was undone")
+	}
+
+	if minefield.State != GameLose {
+		t.Errorf("state after opening a mine = %d, want lose", minefield.State)
+	}
+}
+
+// fieldStates returns states of all cells row by row
+func fieldStates(m *Minefield) []int {
+	var states []int
+	for _, row := range m.Field {
+		for _, cell := range row {
+			states = append(states, cell.State)
+		}
+	}
+
+	return states
+}
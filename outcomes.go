@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/floodcode/tbf"
+)
+
+// Outcome contains counts of won and lost games of a single difficulty
+type Outcome struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+// Games returns count of finished games
+func (o Outcome) Games() int {
+	return o.Wins + o.Losses
+}
+
+// OutcomeStats contains anonymous outcomes of games by their difficulty,
+// they are collected only when telemetry config field is enabled
+type OutcomeStats struct {
+	mu       sync.RWMutex
+	outcomes map[string]Outcome
+}
+
+func newOutcomeStats() *OutcomeStats {
+	return &OutcomeStats{
+		outcomes: map[string]Outcome{},
+	}
+}
+
+// Add counts outcome of the game with the difficulty
+func (s *OutcomeStats) Add(difficulty Difficulty, won bool) {
+	if !botConfig.Telemetry {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outcome := s.outcomes[difficulty.String()]
+	if won {
+		outcome.Wins++
+	} else {
+		outcome.Losses++
+	}
+
+	s.outcomes[difficulty.String()] = outcome
+}
+
+// Snapshot returns copy of outcomes of all difficulties
+func (s *OutcomeStats) Snapshot() map[string]Outcome {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[string]Outcome, len(s.outcomes))
+	for difficulty, outcome := range s.outcomes {
+		snapshot[difficulty] = outcome
+	}
+
+	return snapshot
+}
+
+// Load replaces outcomes of all difficulties with given ones
+func (s *OutcomeStats) Load(outcomes map[string]Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outcomes = make(map[string]Outcome, len(outcomes))
+	for difficulty, outcome := range outcomes {
+		s.outcomes[difficulty] = outcome
+	}
+}
+
+// globalStatsAction shows win rates of the most played difficulties
+func globalStatsAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	if !botConfig.Telemetry {
+		req.QuickMessage(tr(lang, "globalstats.disabled"))
+		return
+	}
+
+	snapshot := outcomes.Snapshot()
+	if len(snapshot) == 0 {
+		req.QuickMessage(tr(lang, "globalstats.empty"))
+		return
+	}
+
+	difficulties := make([]string, 0, len(snapshot))
+	for difficulty := range snapshot {
+		difficulties = append(difficulties, difficulty)
+	}
+
+	sort.Slice(difficulties, func(i, j int) bool {
+		a, b := snapshot[difficulties[i]], snapshot[difficulties[j]]
+		if a.Games() != b.Games() {
+			return a.Games() > b.Games()
+		}
+
+		return difficulties[i] < difficulties[j]
+	})
+
+	if len(difficulties) > scoreboardSize {
+		difficulties = difficulties[:scoreboardSize]
+	}
+
+	lines := []string{"*" + tr(lang, "globalstats.title") + "*"}
+	for _, difficulty := range difficulties {
+		outcome := snapshot[difficulty]
+		lines = append(lines, fmt.Sprintf("%s — %s", difficulty,
+			tr(lang, "globalstats.line", outcome.Games(), outcome.Wins*100/outcome.Games())))
+	}
+
+	req.QuickMessageMD(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestOutcomeStatsAdd(t *testing.T) {
+	easy, hard := difficulties["easy"], difficulties["hard"]
+	results := []struct {
+		difficulty Difficulty
+		won        bool
+	}{
+		{easy, true}, {easy, true}, {easy, false}, {hard, false},
+	}
+
+	tests := []struct {
+		name      string
+		telemetry bool
+		want      map[string]Outcome
+	}{
+		{"enabled", true, map[string]Outcome{easy.String(): {Wins: 2, Losses: 1}, hard.String(): {Losses: 1}}},
+		{"disabled", false, map[string]Outcome{}},
+	}
+
+	for _, test := range tests {
+		withConfig(t, BotConfig{Telemetry: test.telemetry})
+		stats := newOutcomeStats()
+		for _, result := range results {
+			stats.Add(result.difficulty, result.won)
+		}
+
+		snapshot := stats.Snapshot()
+		if len(snapshot) != len(test.want) {
+			t.Errorf("%s: %d buckets, want %d", test.name, len(snapshot), len(test.want))
+		}
+
+		for difficulty, want := range test.want {
+			if got := snapshot[difficulty]; got != want {
+				t.Errorf("%s: outcome of %s = %+v, want %+v", test.name, difficulty, got, want)
+			}
+		}
+	}
+}
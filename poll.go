@@ -0,0 +1,52 @@
+package main
+
+import (
+	"time"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+// pollTimeout is the long polling timeout of getUpdates requests in seconds
+const pollTimeout = 30
+
+// poller receives updates with getUpdates requests, unlike tbf.Poll it reads
+// pollDelay before each pause so /setdelay applies to the next iteration
+type poller struct {
+	fetch  func(offset int) ([]tgbot.Update, error)
+	handle func(update tgbot.Update)
+	sleep  func(time.Duration)
+
+	// offset contains ID of the next expected update, it's kept between
+	// restarts so handled updates aren't received again
+	offset int
+}
+
+func newPoller(bot *tbf.TelegramBotFramework) *poller {
+	return &poller{
+		fetch: func(offset int) ([]tgbot.Update, error) {
+			return bot.Bot().GetUpdates(tgbot.GetUpdatesConfig{Offset: offset, Timeout: pollTimeout})
+		},
+		handle: bot.HandleUpdate,
+		sleep:  time.Sleep,
+	}
+}
+
+// poll passes received updates to the handlers until a request fails, each
+// update is handled in its own goroutine so a handler waiting for the next
+// message with WaitNext doesn't block receiving that message
+func (p *poller) poll() error {
+	for {
+		updates, err := p.fetch(p.offset)
+		if err != nil {
+			return err
+		}
+
+		for _, update := range updates {
+			p.offset = update.UpdateID + 1
+			go p.handle(update)
+		}
+
+		p.sleep(time.Duration(pollDelay.Load()) * time.Millisecond)
+	}
+}
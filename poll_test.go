@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floodcode/tgbot"
+)
+
+func TestPollerReadsDelayEachIteration(t *testing.T) {
+	defer pollDelay.Store(pollDelay.Load())
+	pollDelay.Store(300)
+
+	errStop := errors.New("stop")
+	var slept []time.Duration
+	var mu sync.Mutex
+	var handled sync.WaitGroup
+	var handledIDs []int
+	handled.Add(2)
+	requests := 0
+	p := &poller{
+		fetch: func(offset int) ([]tgbot.Update, error) {
+			requests++
+			switch requests {
+			case 1:
+				return []tgbot.Update{{UpdateID: 10}, {UpdateID: 11}}, nil
+			case 2:
+				if offset != 12 {
+					t.Errorf("offset = %d, want 12", offset)
+				}
+
+				pollDelay.Store(1200)
+				return nil, nil
+			default:
+				return nil, errStop
+			}
+		},
+		handle: func(update tgbot.Update) {
+			mu.Lock()
+			defer mu.Unlock()
+			handledIDs = append(handledIDs, update.UpdateID)
+			handled.Done()
+		},
+		sleep: func(d time.Duration) {
+			slept = append(slept, d)
+		},
+	}
+
+	if err := p.poll(); err != errStop {
+		t.Fatalf("poll() = %v, want %v", err, errStop)
+	}
+
+	want := []time.Duration{300 * time.Millisecond, 1200 * time.Millisecond}
+	if !slices.Equal(slept, want) {
+		t.Errorf("slept %v, want %v", slept, want)
+	}
+
+	handled.Wait()
+	slices.Sort(handledIDs)
+	if !slices.Equal(handledIDs, []int{10, 11}) {
+		t.Errorf("handled %v, want [10 11]", handledIDs)
+	}
+
+	if p.offset != 12 {
+		t.Errorf("offset after restart = %d, want 12", p.offset)
+	}
+}
+
+func TestPollerDeliversAwaitedUpdate(t *testing.T) {
+	defer pollDelay.Store(pollDelay.Load())
+	pollDelay.Store(0)
+
+	errStop := errors.New("stop")
+	next := make(chan tgbot.Update)
+	done := make(chan int)
+	requests := 0
+	p := &poller{
+		fetch: func(offset int) ([]tgbot.Update, error) {
+			requests++
+			switch requests {
+			case 1, 2:
+				return []tgbot.Update{{UpdateID: requests}}, nil
+			default:
+				return nil, errStop
+			}
+		},
+		// the first update waits for the next one like a prompt waiting
+		// for the answer with WaitNext
+		handle: func(update tgbot.Update) {
+			if update.UpdateID == 1 {
+				done <- (<-next).UpdateID
+				return
+			}
+
+			next <- update
+		},
+		sleep: func(time.Duration) {},
+	}
+
+	go p.poll()
+
+	select {
+	case id := <-done:
+		if id != 2 {
+			t.Errorf("waiting handler got update %d, want 2", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting handler didn't get the next update")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter contains token buckets of users and can be safely used from multiple goroutines
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[int]*tokenBucket
+
+	// now returns current time, replaced in tests
+	now func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter creates limiter allowing rate actions per second
+// with up to burst actions at once
+func newRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   math.Max(float64(burst), 1),
+		buckets: map[int]*tokenBucket{},
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the user's bucket and reports if there was one
+func (l *RateLimiter) Allow(userID int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.buckets[userID]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[userID] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updated).Seconds()
+	bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.rate)
+	bucket.updated = now
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+// Evict removes buckets which are full by the time, they are recreated full
+// on the next action of their users. Count of removed buckets is returned
+func (l *RateLimiter) Evict(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	evicted := 0
+	for userID, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, userID)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// evictBuckets periodically removes full buckets of users who stopped playing
+func evictBuckets(limiter *RateLimiter) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if evicted := limiter.Evict(now); evicted > 0 {
+			slog.Debug("Rate limiter buckets evicted", "count", evicted)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := newRateLimiter(1, 2)
+	for i, want := range []bool{true, true, false} {
+		if got := limiter.Allow(1); got != want {
+			t.Errorf("action %d: Allow() = %v, want %v", i+1, got, want)
+		}
+	}
+
+	if !limiter.Allow(2) {
+		t.Error("bucket of another user is empty")
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(2, 3)
+	limiter.now = func() time.Time { return now }
+
+	tests := []struct {
+		elapsed time.Duration
+		actions int
+		allowed int
+	}{
+		{0, 5, 3},
+		{100 * time.Millisecond, 1, 0},
+		{400 * time.Millisecond, 2, 1},
+		{time.Second, 3, 2},
+		{time.Hour, 5, 3},
+	}
+
+	for i, test := range tests {
+		now = now.Add(test.elapsed)
+		allowed := 0
+		for action := 0; action < test.actions; action++ {
+			if limiter.Allow(1) {
+				allowed++
+			}
+		}
+
+		if allowed != test.allowed {
+			t.Errorf("step %d: %d actions allowed after %v, want %d", i+1, allowed, test.elapsed, test.allowed)
+		}
+	}
+}
+
+func TestRateLimiterEvict(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+	limiter.Allow(1)
+	limiter.Allow(1)
+	limiter.Allow(2)
+
+	if evicted := limiter.Evict(now); evicted != 0 {
+		t.Errorf("Evict() of used buckets = %d, want 0", evicted)
+	}
+
+	// bucket of user 2 refills in a second, user 1 needs two
+	if evicted := limiter.Evict(now.Add(1500 * time.Millisecond)); evicted != 1 {
+		t.Errorf("Evict() after refill of one bucket = %d, want 1", evicted)
+	}
+
+	if evicted := limiter.Evict(now.Add(3 * time.Second)); evicted != 1 {
+		t.Errorf("Evict() after refill of all buckets = %d, want 1", evicted)
+	}
+
+	if len(limiter.buckets) != 0 {
+		t.Errorf("%d buckets left, want 0", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	// maxKeyboardBytes is conservative budget of serialized inline keyboard,
+	// Telegram rejects messages with larger reply markup
+	maxKeyboardBytes       = 8192
+	maxCallbackDataBytes   = 64
+	keyboardButtonOverhead = len(`{"text":"","callback_data":""},`)
+)
+
+func renderMinefield(game *Game) *tgbot.ReplyMarkup {
+	buttons := renderCells(game, botConfig.CompactBoard)
+	if size := keyboardSize(buttons); size > maxKeyboardBytes && !botConfig.CompactBoard {
+		slog.Warn("Board keyboard is over size budget, using compact payload", "game", game.Key(), "size", size)
+		buttons = renderCells(game, true)
+	}
+
+	if game.Minefield.State == GameRunning {
+		if paginated(game.Minefield) {
+			buttons = append(buttons, renderNavigation(game))
+		}
+
+		buttons = append(buttons, []tgbot.InlineKeyboardButton{{
+			Text: renderMode(game),
+			CallbackData: encodeCallbackData(CellCallbackData{
+				Action: actionToggleMode,
+			}),
+		}, {
+			Text: tr(game.Language, "game.give_up"),
+			CallbackData: encodeCallbackData(CellCallbackData{
+				Action: actionGiveUp,
+			}),
+		}})
+	} else {
+		buttons = append(buttons, []tgbot.InlineKeyboardButton{{
+			Text: tr(game.Language, "game.play_again"),
+			CallbackData: encodeCallbackData(CellCallbackData{
+				Action: actionRestart,
+			}),
+		}})
+	}
+
+	return tgbot.InlineKeyboardMarkup(buttons)
+}
+
+// renderMirror renders board of read-only mirror, its buttons
+// only tell that the board can't be played
+func renderMirror(game *Game) *tgbot.ReplyMarkup {
+	buttons := renderCells(game, botConfig.CompactBoard)
+	callbackData := encodeCallbackData(CellCallbackData{
+		Action: actionWatch,
+	})
+
+	for _, row := range buttons {
+		for col := range row {
+			row[col].CallbackData = callbackData
+		}
+	}
+
+	return tgbot.InlineKeyboardMarkup(buttons)
+}
+
+// renderCells renders cell buttons of the visible window of the board, their
+// payload contains absolute coordinates of the cells. Compact boards use no-op
+// payload for settled cells which can't be played anymore
+func renderCells(game *Game, compact bool) [][]tgbot.InlineKeyboardButton {
+	minefield := game.Minefield
+	theme := gameTheme(game)
+	field := minefield.Field
+	top, left, height, width := viewport(game)
+	buttons := make([][]tgbot.InlineKeyboardButton, 0, height)
+	for row := top; row < top+height; row++ {
+		cells := make([]tgbot.InlineKeyboardButton, 0, width)
+		for col := left; col < left+width; col++ {
+			cell := field[row][col]
+			callbackData := CellCallbackData{
+				Row: row,
+				Col: col,
+			}
+
+			if compact && minefield.Settled(row, col) {
+				callbackData = CellCallbackData{
+					Action: actionNoop,
+				}
+			}
+
+			cells = append(cells, tgbot.InlineKeyboardButton{
+				Text:         renderCell(cell, minefield.State, theme),
+				CallbackData: encodeCallbackData(callbackData),
+			})
+		}
+
+		buttons = append(buttons, cells)
+	}
+
+	return buttons
+}
+
+// keyboardSize estimates size of the keyboard serialized to JSON
+func keyboardSize(buttons [][]tgbot.InlineKeyboardButton) int {
+	size := 0
+	for _, row := range buttons {
+		for _, button := range row {
+			size += len(button.Text) + len(button.CallbackData) + keyboardButtonOverhead
+		}
+	}
+
+	return size
+}
+
+// estimateKeyboardSize returns upper bound of keyboard size of the board
+// with given dimensions, cells are measured with the widest glyph of all themes
+// and only the visible window of large boards is counted
+func estimateKeyboardSize(width, height int) int {
+	glyphSize := 0
+	for _, theme := range themes {
+		glyphSize = max(glyphSize, len(theme.Closed), len(theme.Flagged), len(theme.Question), len(theme.Exploded))
+		for _, glyph := range theme.Types {
+			glyphSize = max(glyphSize, len(glyph))
+		}
+	}
+
+	callbackData := encodeCallbackData(CellCallbackData{Row: height - 1, Col: width - 1})
+	cellSize := glyphSize + len(callbackData) + keyboardButtonOverhead
+	controlSize := 2 * (2*maxCallbackDataBytes + keyboardButtonOverhead)
+	if width > viewportSize || height > viewportSize {
+		controlSize += len(scrollGlyphs) * (2*maxCallbackDataBytes + keyboardButtonOverhead)
+	}
+
+	return min(width, viewportSize)*min(height, viewportSize)*cellSize + controlSize
+}
+
+func renderText(game *Game, title string) string {
+	minefield := game.Minefield
+	text := fmt.Sprintf(
+		"%s — 💣 %d/%d — seed:%d",
+		title, minefield.Mines-minefield.countState(StateFlagged), minefield.Mines, minefield.Seed,
+	)
+
+	if game.Duel() && minefield.State == GameRunning {
+		text += "\n" + tr(game.Language, "duel.turn", game.CurrentPlayer().Name)
+	}
+
+	if settings.Get(game.OwnerID).Verbose {
+		text += "\n" + renderTally(game)
+	}
+
+	return text
+}
+
+// renderTally describes count of not opened cells and opened numbers like 1×5 2×3
+func renderTally(game *Game) string {
+	numbers, closed := tallyField(game.Minefield)
+	var parts []string
+	for number, count := range numbers {
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d×%d", number+1, count))
+		}
+	}
+
+	text := "—"
+	if len(parts) > 0 {
+		text = strings.Join(parts, " ")
+	}
+
+	return tr(game.Language, "verbose.line", closed, text)
+}
+
+// tallyField counts opened number cells by their number starting from 1
+// and cells which aren't opened yet
+func tallyField(minefield *Minefield) ([8]int, int) {
+	var numbers [8]int
+	closed := 0
+	for _, cells := range minefield.Field {
+		for _, cell := range cells {
+			switch {
+			case cell.State != StateOpened:
+				closed++
+			case cell.Type >= Type1 && cell.Type <= Type8:
+				numbers[cell.Type-Type1]++
+			}
+		}
+	}
+
+	return numbers, closed
+}
+
+// renderSummary describes results of the finished game
+func renderSummary(game *Game) string {
+	minefield := game.Minefield
+	safeCells := minefield.Width*minefield.Height - minefield.Mines
+	opened, flags, correctFlags := 0, 0, 0
+	for _, cells := range minefield.Field {
+		for _, cell := range cells {
+			switch {
+			case cell.State == StateOpened && cell.Type != TypeMine:
+				opened++
+			case cell.State == StateFlagged:
+				flags++
+				if cell.Type == TypeMine {
+					correctFlags++
+				}
+			}
+		}
+	}
+
+	lines := []string{
+		tr(game.Language, "summary.opened", opened, safeCells),
+		tr(game.Language, "summary.flags", flags, correctFlags),
+	}
+
+	if minefield.State == GameLose {
+		lines = append(lines, tr(game.Language, "summary.remaining", safeCells-opened))
+	}
+
+	for _, player := range game.Players {
+		lines = append(lines, tr(game.Language, "summary.player", player.Name, player.Revealed))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderMode(game *Game) string {
+	theme := gameTheme(game)
+	if game.FlagMode {
+		return tr(game.Language, "game.mode_flag", theme.Flagged)
+	}
+
+	return tr(game.Language, "game.mode_open", theme.Closed)
+}
+
+// renderGrid renders minefield as monospaced text grid, mines are shown
+// only on finished boards
+func renderGrid(minefield *Minefield) string {
+	lines := make([]string, 0, minefield.Height)
+	for _, cells := range minefield.Field {
+		chars := make([]string, 0, minefield.Width)
+		for _, cell := range cells {
+			chars = append(chars, renderGridCell(cell, minefield.State))
+		}
+
+		lines = append(lines, strings.Join(chars, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderGridCell(cell Cell, gameState int) string {
+	if cell.Type == TypeMine && gameState != GameRunning {
+		return "*"
+	}
+
+	switch cell.State {
+	case StateClosed:
+		return "."
+	case StateFlagged:
+		return "F"
+	case StateQuestion:
+		return "?"
+	}
+
+	if cell.Type == TypeEmpty {
+		return " "
+	}
+
+	return fmt.Sprint(cell.Type - TypeEmpty)
+}
+
+// renderThemeSample renders row of typical cells with the theme
+func renderThemeSample(theme Theme) string {
+	sample := []struct {
+		cell      Cell
+		gameState int
+	}{
+		{Cell{Type: TypeEmpty, State: StateClosed}, GameRunning},
+		{Cell{Type: TypeMine, State: StateFlagged}, GameRunning},
+		{Cell{Type: Type1, State: StateOpened}, GameRunning},
+		{Cell{Type: Type2, State: StateOpened}, GameRunning},
+		{Cell{Type: Type3, State: StateOpened}, GameRunning},
+		{Cell{Type: TypeMine, State: StateClosed}, GameLose},
+		{Cell{Type: TypeMine, State: StateOpened}, GameLose},
+	}
+
+	var text strings.Builder
+	for _, sampleCell := range sample {
+		text.WriteString(renderCell(sampleCell.cell, sampleCell.gameState, theme))
+	}
+
+	return text.String()
+}
+
+func renderCell(cell Cell, gameState int, theme Theme) string {
+	if cell.Type == TypeMine && gameState == GameLose {
+		if cell.State == StateOpened {
+			return theme.Exploded
+		}
+
+		return theme.Types[TypeMine]
+	}
+
+	if cell.Type == TypeMine && gameState == GameWin {
+		return theme.Flagged
+	}
+
+	stateChars := map[int]string{
+		StateClosed:   theme.Closed,
+		StateFlagged:  theme.Flagged,
+		StateQuestion: theme.Question,
+	}
+
+	if val, ok := stateChars[cell.State]; ok {
+		return val
+	}
+
+	if val, ok := theme.Types[cell.Type]; ok {
+		return val
+	}
+
+	return fmt.Sprint(cell.Type)
+}
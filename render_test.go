@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCell(t *testing.T) {
+	theme := getTheme(defaultTheme)
+	mine := Cell{Type: TypeMine}
+	tests := []struct {
+		name  string
+		cell  Cell
+		state int
+		want  string
+	}{
+		{"closed mine while running", mine, GameRunning, theme.Closed},
+		{"closed mine on win", mine, GameWin, theme.Flagged},
+		{"closed mine on loss", mine, GameLose, theme.Types[TypeMine]},
+		{"exploded mine", Cell{Type: TypeMine, State: StateOpened}, GameLose, theme.Exploded},
+		{"opened number", Cell{Type: Type2, State: StateOpened}, GameWin, theme.Types[Type2]},
+		{"flagged number", Cell{Type: Type2, State: StateFlagged}, GameRunning, theme.Flagged},
+	}
+
+	for _, test := range tests {
+		if got := renderCell(test.cell, test.state, theme); got != test.want {
+			t.Errorf("%s: renderCell() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRenderWonBoard(t *testing.T) {
+	game := &Game{Minefield: testMinefield("*..", "...", "..*")}
+	game.Minefield.Flag(0, 0)
+	game.Minefield.Open(0, 2)
+	game.Minefield.Open(2, 0)
+	if game.Minefield.State != GameWin {
+		t.Fatalf("state = %d, want win", game.Minefield.State)
+	}
+
+	theme := getTheme(game.Theme)
+	for row, cells := range game.Minefield.Field {
+		for col, cell := range cells {
+			text := renderCell(cell, game.Minefield.State, theme)
+			if flagged := text == theme.Flagged; flagged != (cell.Type == TypeMine) {
+				t.Errorf("cell %d,%d rendered as %q, mine %v", row, col, text, cell.Type == TypeMine)
+			}
+		}
+	}
+}
+
+func TestRenderRectangularBoard(t *testing.T) {
+	game := &Game{Minefield: newMinefield(4, 8, 5, 1)}
+	buttons := renderCells(game, false)
+	if len(buttons) != 8 {
+		t.Fatalf("keyboard has %d rows, want 8", len(buttons))
+	}
+
+	for row, cells := range buttons {
+		if len(cells) != 4 {
+			t.Fatalf("row %d has %d buttons, want 4", row, len(cells))
+		}
+
+		for col, button := range cells {
+			data, err := decodeCallbackData(button.CallbackData)
+			if err != nil || data.Row != row || data.Col != col {
+				t.Errorf("button %d,%d has callback data %+v, %v", row, col, data, err)
+			}
+		}
+	}
+
+	data, _ := decodeCallbackData(buttons[6][1].CallbackData)
+	game.Minefield.Open(data.Row, data.Col)
+	if state := game.Minefield.Field[6][1].State; state != StateOpened {
+		t.Errorf("tapped cell 6,1 has state %d, want opened", state)
+	}
+}
+
+func TestEstimateKeyboardSize(t *testing.T) {
+	estimate := estimateKeyboardSize(8, 8)
+	if estimate > maxKeyboardBytes {
+		t.Errorf("estimate of 8x8 board = %d, over %d bytes limit", estimate, maxKeyboardBytes)
+	}
+
+	for name := range themes {
+		game := &Game{Minefield: newMinefield(8, 8, 10, 1), Theme: name}
+		game.Minefield.Open(0, 0)
+		game.Minefield.State = GameLose
+		if size := keyboardSize(renderCells(game, false)); size > estimate {
+			t.Errorf("%s theme: keyboard of 8x8 board has %d bytes, estimate is %d", name, size, estimate)
+		}
+	}
+
+	if err := validateKeyboard(8, 8); err != nil {
+		t.Errorf("validateKeyboard(8, 8) = %v, want nil", err)
+	}
+}
+
+func TestTallyField(t *testing.T) {
+	minefield := testMinefield("*..*", "....", "**..")
+	for _, position := range []Position{{0, 0}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {1, 3}, {2, 2}, {2, 3}} {
+		minefield.Field[position.Row][position.Col].State = StateOpened
+	}
+
+	minefield.Field[0][3].State = StateFlagged
+	minefield.Field[2][0].State = StateQuestion
+
+	numbers, closed := tallyField(minefield)
+	if want := [8]int{3, 1, 2}; numbers != want {
+		t.Errorf("numbers = %v, want %v", numbers, want)
+	}
+
+	if closed != 4 {
+		t.Errorf("closed = %d, want 4", closed)
+	}
+
+	if tally := renderTally(&Game{Minefield: minefield}); !strings.Contains(tally, "1×3 2×1 3×2") {
+		t.Errorf("renderTally() = %q, want 1×3 2×1 3×2", tally)
+	}
+}
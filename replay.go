@@ -0,0 +1,218 @@
+package main
+
+import (
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	maxReplays     = 5
+	maxReplaySteps = 50
+	replayDelay    = time.Second
+)
+
+// Replay contains finished minefield with history of its moves
+type Replay struct {
+	Minefield  *Minefield `json:"minefield"`
+	Theme      string     `json:"theme,omitempty"`
+	FinishedAt time.Time  `json:"finished_at"`
+}
+
+// ReplayStore contains latest finished games of users and can be safely used from multiple goroutines
+type ReplayStore struct {
+	mu      sync.RWMutex
+	replays map[int][]Replay
+}
+
+func newReplayStore() *ReplayStore {
+	return &ReplayStore{
+		replays: map[int][]Replay{},
+	}
+}
+
+// newReplay copies minefield of the finished game with its history
+func newReplay(game *Game) Replay {
+	minefield := game.Minefield.clone()
+	minefield.History = append([][]int(nil), game.Minefield.History...)
+	minefield.Moves = append([]Move(nil), game.Minefield.Moves...)
+	return Replay{
+		Minefield:  minefield,
+		Theme:      game.Theme,
+		FinishedAt: time.Now(),
+	}
+}
+
+// Add stores replay of the user keeping only maxReplays latest ones
+func (s *ReplayStore) Add(userID int, replay Replay) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replays := append(s.replays[userID], replay)
+	if len(replays) > maxReplays {
+		replays = replays[len(replays)-maxReplays:]
+	}
+
+	s.replays[userID] = replays
+}
+
+// Get returns n-th latest replay of the user starting from 1
+func (s *ReplayStore) Get(userID, n int) (Replay, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	replays := s.replays[userID]
+	if n < 1 || n > len(replays) {
+		return Replay{}, false
+	}
+
+	return replays[len(replays)-n], true
+}
+
+// Snapshot returns copy of replays of all users
+func (s *ReplayStore) Snapshot() map[int][]Replay {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[int][]Replay, len(s.replays))
+	for userID, replays := range s.replays {
+		snapshot[userID] = append([]Replay(nil), replays...)
+	}
+
+	return snapshot
+}
+
+// Load replaces replays of all users with given ones
+func (s *ReplayStore) Load(replays map[int][]Replay) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replays = make(map[int][]Replay, len(replays))
+	for userID, userReplays := range replays {
+		s.replays[userID] = userReplays
+	}
+}
+
+// replayAction replays the latest finished game of the user,
+// /replay <n> replays n-th latest one
+func replayAction(req tbf.Request) {
+	if replay, ok := userReplay(req); ok {
+		sendReplay(req, replay)
+	}
+}
+
+// userReplay returns replay chosen by <n> argument of the command, the latest
+// one is returned without it. The user is told when there's no such replay
+func userReplay(req tbf.Request) (Replay, bool) {
+	lang := userLanguage(req.Message.From)
+	n := 1
+	if args := commandArgs(req.Message.Text); len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil {
+			req.QuickMessage(tr(lang, "replay.number", maxReplays))
+			return Replay{}, false
+		}
+	}
+
+	replay, ok := replays.Get(req.Message.From.ID, n)
+	if !ok {
+		req.QuickMessage(tr(lang, "replay.not_found", maxReplays))
+		return Replay{}, false
+	}
+
+	return replay, true
+}
+
+// sendReplay sends board of the replay and plays it
+func sendReplay(req tbf.Request, replay Replay) {
+	lang := userLanguage(req.Message.From)
+	if len(replay.Minefield.History) == 0 {
+		req.QuickMessage(tr(lang, "replay.empty"))
+		return
+	}
+
+	game := replayGame(replay)
+	game.Language = lang
+	game.ChatID = req.Message.Chat.ID
+	msg, err := sendMessage(req.Bot, tgbot.SendMessageConfig{
+		ChatID:      tgbot.ChatID(game.ChatID),
+		Text:        renderText(game, tr(lang, "replay.title", 0, len(replay.Minefield.History))),
+		ReplyMarkup: renderMirror(game),
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send replay", "chat_id", game.ChatID, "error", err)
+		return
+	}
+
+	game.MessageID = msg.MessageID
+	go playReplay(req.Bot, game, replay, req.Message.From.ID)
+}
+
+// replayGame creates read-only game with the replay's minefield before the first move,
+// cells opened without a move, like the start of daily board, are kept opened
+func replayGame(replay Replay) *Game {
+	minefield := replay.Minefield.clone()
+	minefield.State = GameRunning
+	for row := range minefield.Field {
+		for col := range minefield.Field[row] {
+			if minefield.Field[row][col].State != StateOpened {
+				minefield.Field[row][col].State = StateClosed
+			}
+		}
+	}
+
+	for _, move := range replay.Minefield.History {
+		for _, index := range move {
+			minefield.Field[index/minefield.Width][index%minefield.Width].State = StateClosed
+		}
+	}
+
+	return &Game{
+		Minefield: minefield,
+		Theme:     replay.Theme,
+		CreatedAt: time.Now(),
+	}
+}
+
+// playReplay opens cells of the replay move by move editing the board, moves are
+// grouped when there are more than maxReplaySteps of them and wait while the user
+// who requested the replay is rate limited
+func playReplay(bot *tgbot.TelegramBot, game *Game, replay Replay, userID int) {
+	moves := replay.Minefield.History
+	movesPerStep := (len(moves) + maxReplaySteps - 1) / maxReplaySteps
+	for move := 0; move < len(moves); {
+		time.Sleep(replayDelay)
+		if limiter != nil && !limiter.Allow(userID) {
+			continue
+		}
+
+		for end := min(move+movesPerStep, len(moves)); move < end; move++ {
+			replayMove(game.Minefield, moves[move])
+		}
+
+		if move == len(moves) {
+			game.Minefield.State = replay.Minefield.State
+		}
+
+		config := game.EditConfig()
+		config.Text = renderText(game, tr(game.Language, "replay.title", move, len(moves)))
+		config.ReplyMarkup = renderMirror(game)
+		if err := editMessage(bot, config); err != nil {
+			slog.Error("Unable to update replay", "chat_id", game.ChatID, "error", err)
+			return
+		}
+	}
+}
+
+// replayMove opens cells of the recorded move
+func replayMove(minefield *Minefield, move []int) {
+	for _, index := range move {
+		minefield.Field[index/minefield.Width][index%minefield.Width].State = StateOpened
+	}
+}
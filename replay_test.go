@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestReplayReconstruction(t *testing.T) {
+	game := &Game{Minefield: testMinefield("..*..", "..*..", ".....", "*...*"), Theme: "dark"}
+	for _, tap := range []Position{{0, 0}, {0, 3}, {3, 2}, {3, 4}} {
+		game.Minefield.Open(tap.Row, tap.Col)
+	}
+
+	if game.Minefield.State != GameLose {
+		t.Fatalf("state = %d, want loss", game.Minefield.State)
+	}
+
+	replay := newReplay(game)
+	replayed := replayGame(replay)
+	if replayed.Theme != game.Theme || replayed.Minefield.countState(StateOpened) != 0 {
+		t.Fatalf("replay starts with %d opened cells and theme %q", replayed.Minefield.countState(StateOpened), replayed.Theme)
+	}
+
+	for _, move := range replay.Minefield.History {
+		replayMove(replayed.Minefield, move)
+	}
+
+	for row := range game.Minefield.Field {
+		for col, cell := range game.Minefield.Field[row] {
+			replayedCell := replayed.Minefield.Field[row][col]
+			if replayedCell.Type != cell.Type || (replayedCell.State == StateOpened) != (cell.State == StateOpened) {
+				t.Errorf("cell %d,%d replayed as %+v, want %+v", row, col, replayedCell, cell)
+			}
+		}
+	}
+
+	game.Minefield.Field[0][0].State = StateClosed
+	if replay.Minefield.Field[0][0].State != StateOpened {
+		t.Error("replay shares field with the game")
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	scoreboardSize = 10
+)
+
+// Score contains results of a single player
+type Score struct {
+	UserID     int                      `json:"user_id"`
+	Name       string                   `json:"name"`
+	Wins       int                      `json:"wins"`
+	Losses     int                      `json:"losses"`
+	Abandoned  int                      `json:"abandoned,omitempty"`
+	Streak     int                      `json:"streak,omitempty"`
+	BestStreak int                      `json:"best_streak,omitempty"`
+	BestTimes  map[string]time.Duration `json:"best_times,omitempty"`
+}
+
+// Games returns count of finished games
+func (s Score) Games() int {
+	return s.Wins + s.Losses
+}
+
+// WinRate returns percentage of won games
+func (s Score) WinRate() int {
+	if s.Games() == 0 {
+		return 0
+	}
+
+	return s.Wins * 100 / s.Games()
+}
+
+func (s Score) clone() Score {
+	bestTimes := make(map[string]time.Duration, len(s.BestTimes))
+	for difficulty, elapsed := range s.BestTimes {
+		bestTimes[difficulty] = elapsed
+	}
+
+	s.BestTimes = bestTimes
+	return s
+}
+
+// Scoreboard contains scores of all players and can be safely used from multiple goroutines
+type Scoreboard struct {
+	mu     sync.RWMutex
+	scores map[int]*Score
+}
+
+func newScoreboard() *Scoreboard {
+	return &Scoreboard{
+		scores: map[int]*Score{},
+	}
+}
+
+// AddWin increments wins count and streak of the player and updates best time
+// for the difficulty, current streak is returned and if it's the best one
+func (s *Scoreboard) AddWin(userID int, name string, difficulty Difficulty, elapsed time.Duration) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score := s.getScore(userID, name)
+	score.Wins++
+	score.Streak++
+	newBest := score.Streak > score.BestStreak
+	if newBest {
+		score.BestStreak = score.Streak
+	}
+
+	best, ok := score.BestTimes[difficulty.String()]
+	if !ok || elapsed < best {
+		score.BestTimes[difficulty.String()] = elapsed
+	}
+
+	return score.Streak, newBest
+}
+
+// AddLoss increments losses count of the player and resets their streak,
+// broken streak is returned
+func (s *Scoreboard) AddLoss(userID int, name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score := s.getScore(userID, name)
+	score.Losses++
+	streak := score.Streak
+	score.Streak = 0
+	return streak
+}
+
+// AddAbandoned increments count of games the player left unfinished
+func (s *Scoreboard) AddAbandoned(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.scores[userID]
+	if !ok {
+		score = &Score{UserID: userID}
+		s.scores[userID] = score
+	}
+
+	score.Abandoned++
+}
+
+// Get returns score of the player
+func (s *Scoreboard) Get(userID int) (Score, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	score, ok := s.scores[userID]
+	if !ok {
+		return Score{}, false
+	}
+
+	return score.clone(), true
+}
+
+func (s *Scoreboard) getScore(userID int, name string) *Score {
+	score, ok := s.scores[userID]
+	if !ok {
+		score = &Score{UserID: userID}
+		s.scores[userID] = score
+	}
+
+	if score.BestTimes == nil {
+		score.BestTimes = map[string]time.Duration{}
+	}
+
+	score.Name = name
+	return score
+}
+
+// Top returns up to n scores ordered by wins count
+func (s *Scoreboard) Top(n int) []Score {
+	scores := s.Snapshot()
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Wins != scores[j].Wins {
+			return scores[i].Wins > scores[j].Wins
+		}
+
+		return scores[i].UserID < scores[j].UserID
+	})
+
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+
+	return scores
+}
+
+// Snapshot returns copy of all scores
+func (s *Scoreboard) Snapshot() []Score {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := make([]Score, 0, len(s.scores))
+	for _, score := range s.scores {
+		scores = append(scores, score.clone())
+	}
+
+	return scores
+}
+
+// Load replaces all scores with given ones
+func (s *Scoreboard) Load(scores []Score) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scores = make(map[int]*Score, len(scores))
+	for i := range scores {
+		score := scores[i]
+		s.scores[score.UserID] = &score
+	}
+}
+
+// Reset removes scores of all players
+func (s *Scoreboard) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scores = map[int]*Score{}
+}
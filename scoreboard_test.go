@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+var testDifficulty = Difficulty{Width: 8, Height: 8, Mines: 10}
+
+func TestScoreboardTop(t *testing.T) {
+	scoreboard := newScoreboard()
+	for _, userID := range []int{2, 3, 1, 3, 2, 3, 4, 4} {
+		scoreboard.AddWin(userID, "", testDifficulty, time.Minute)
+	}
+
+	scoreboard.AddLoss(5, "")
+
+	want := []struct{ userID, wins int }{{3, 3}, {2, 2}, {4, 2}}
+	top := scoreboard.Top(len(want))
+	if len(top) != len(want) {
+		t.Fatalf("Top() returned %d scores, want %d", len(top), len(want))
+	}
+
+	for i, score := range top {
+		if score.UserID != want[i].userID || score.Wins != want[i].wins {
+			t.Errorf("place %d: user %d with %d wins, want user %d with %d wins",
+				i+1, score.UserID, score.Wins, want[i].userID, want[i].wins)
+		}
+	}
+}
+
+func TestScoreboardStreak(t *testing.T) {
+	scoreboard := newScoreboard()
+	tests := []struct {
+		won        bool
+		wantStreak int
+		wantBest   bool
+		wantBroken int
+	}{
+		{true, 1, true, 0},
+		{true, 2, true, 0},
+		{false, 0, false, 2},
+		{true, 1, false, 0},
+	}
+
+	for i, test := range tests {
+		if test.won {
+			streak, best := scoreboard.AddWin(1, "", testDifficulty, time.Minute)
+			if streak != test.wantStreak || best != test.wantBest {
+				t.Errorf("game %d: AddWin() = %d, %v, want %d, %v", i+1, streak, best, test.wantStreak, test.wantBest)
+			}
+		} else if broken := scoreboard.AddLoss(1, ""); broken != test.wantBroken {
+			t.Errorf("game %d: AddLoss() = %d, want %d", i+1, broken, test.wantBroken)
+		}
+	}
+
+	score, _ := scoreboard.Get(1)
+	if score.Streak != 1 || score.BestStreak != 2 {
+		t.Errorf("streak %d, best streak %d, want 1, 2", score.Streak, score.BestStreak)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sync"
+)
+
+// UserSettings contains preferences of a single user
+type UserSettings struct {
+	Theme  string `json:"theme,omitempty"`
+	Assist bool   `json:"assist,omitempty"`
+
+	// Verbose adds counts of closed cells and opened numbers to boards of the user
+	Verbose bool `json:"verbose,omitempty"`
+
+	// Glyphs contains glyphs replacing the theme's ones by their kind, e.g. mine
+	Glyphs map[string]string `json:"glyphs,omitempty"`
+}
+
+// SettingsStore contains settings of all users and can be safely used from multiple goroutines
+type SettingsStore struct {
+	mu    sync.RWMutex
+	users map[int]UserSettings
+}
+
+func newSettingsStore() *SettingsStore {
+	return &SettingsStore{
+		users: map[int]UserSettings{},
+	}
+}
+
+// Get returns settings of the user
+func (s *SettingsStore) Get(userID int) UserSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.users[userID]
+}
+
+// Update applies changes to settings of the user
+func (s *SettingsStore) Update(userID int, update func(settings *UserSettings)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := s.users[userID]
+	update(&settings)
+	s.users[userID] = settings
+}
+
+// Snapshot returns copy of settings of all users
+func (s *SettingsStore) Snapshot() map[int]UserSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[int]UserSettings, len(s.users))
+	for userID, settings := range s.users {
+		snapshot[userID] = settings
+	}
+
+	return snapshot
+}
+
+// Load replaces settings of all users with given ones
+func (s *SettingsStore) Load(users map[int]UserSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = make(map[int]UserSettings, len(users))
+	for userID, settings := range users {
+		s.users[userID] = settings
+	}
+}
+
+// ChatSettings contains defaults of a chat applied to games started in it
+type ChatSettings struct {
+	Difficulty string `json:"difficulty,omitempty"`
+	Theme      string `json:"theme,omitempty"`
+	Language   string `json:"language,omitempty"`
+	SingleGame bool   `json:"single_game,omitempty"`
+}
+
+// ChatSettingsStore contains settings of all chats and can be safely used from multiple goroutines
+type ChatSettingsStore struct {
+	mu    sync.RWMutex
+	chats map[string]ChatSettings
+}
+
+func newChatSettingsStore() *ChatSettingsStore {
+	return &ChatSettingsStore{
+		chats: map[string]ChatSettings{},
+	}
+}
+
+// Get returns settings of the chat of the shard
+func (s *ChatSettingsStore) Get(shard string, chatID int) ChatSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.chats[chatKey(shard, chatID)]
+}
+
+// Update applies changes to settings of the chat of the shard
+func (s *ChatSettingsStore) Update(shard string, chatID int, update func(settings *ChatSettings)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := chatKey(shard, chatID)
+	settings := s.chats[key]
+	update(&settings)
+	s.chats[key] = settings
+}
+
+// Snapshot returns copy of settings of all chats
+func (s *ChatSettingsStore) Snapshot() map[string]ChatSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[string]ChatSettings, len(s.chats))
+	for key, settings := range s.chats {
+		snapshot[key] = settings
+	}
+
+	return snapshot
+}
+
+// Load replaces settings of all chats with given ones
+func (s *ChatSettingsStore) Load(chats map[string]ChatSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats = make(map[string]ChatSettings, len(chats))
+	for key, settings := range chats {
+		s.chats[key] = settings
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+var (
+	shardsMu sync.RWMutex
+	shards   = map[*tgbot.TelegramBot]string{}
+)
+
+// newShard creates bot for the token with all handlers registered, requests
+// received by the bot are bound to shard ID so games of different bots
+// don't mix in the game store. Shard ID is empty when only one token is used
+func newShard(token, id string) (*tbf.TelegramBotFramework, error) {
+	bot, err := tbf.New(token)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, command := range botCommands() {
+		name, action := command.Name, command.Action
+		bot.AddRoute(name, func(req tbf.Request) {
+			defer recoverPanic(func() {
+				req.QuickMessage(tr(userLanguage(req.Message.From), "error.internal"))
+			}, "command", name, "user_id", req.Message.From.ID, "chat_id", req.Message.Chat.ID)
+
+			markUpdate()
+			bindShard(req.Bot, id)
+			action(req)
+		})
+	}
+
+	bot.OnCallbackQuery(func(req tbf.CallbackQueryRequest) {
+		defer recoverPanic(func() {
+			req.Answer(tgbot.AnswerCallbackQueryConfig{
+				Text: tr(userLanguage(req.CallbackQuery.From), "error.internal"),
+			})
+		}, "callback", req.CallbackQuery.Data, "user_id", req.CallbackQuery.From.ID)
+
+		markUpdate()
+		bindShard(req.Bot, id)
+		callbackQueryListener(req)
+	})
+
+	bot.OnInlineQuery(func(req tbf.InlineQueryRequest) {
+		defer recoverPanic(nil, "inline_query", req.InlineQuery.Query, "user_id", req.InlineQuery.From.ID)
+		markUpdate()
+		inlineQueryListener(req)
+	})
+
+	bot.OnChosenInlineResult(func(req tbf.ChosenInlineResultRequest) {
+		defer recoverPanic(nil, "inline_result", req.ChosenInlineResult.ResultID, "user_id", req.ChosenInlineResult.From.ID)
+		markUpdate()
+		bindShard(req.Bot, id)
+		chosenInlineResultListener(req)
+	})
+
+	return bot, nil
+}
+
+// recoverPanic should be deferred by handlers, it logs panic of the handler with
+// details of the update so the bot keeps serving other users, notify is called
+// to tell the user about the error
+func recoverPanic(notify func(), args ...any) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	handlerPanics.Inc()
+	slog.Error("Handler panicked", append(args, "panic", r, "stack", string(debug.Stack()))...)
+	if notify != nil {
+		notify()
+	}
+}
+
+// runShard runs the bot until it stops, panic of the bot is
+// returned as error so other shards keep running
+func runShard(bot *tbf.TelegramBotFramework) (err error) {
+	runningShards.Add(1)
+	markUpdate()
+	defer func() {
+		runningShards.Add(-1)
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Bot panicked: %v", r)
+		}
+	}()
+
+	return run(bot)
+}
+
+// shardToken returns ID of the bot the token belongs to
+func shardToken(token string) string {
+	id, _, _ := strings.Cut(token, ":")
+	return id
+}
+
+// bindShard remembers shard ID of the bot, it's done for single bot as well
+// so background jobs can find the bot by shard ID
+func bindShard(bot *tgbot.TelegramBot, id string) {
+	shardsMu.RLock()
+	boundID, bound := shards[bot]
+	shardsMu.RUnlock()
+	if bound && boundID == id {
+		return
+	}
+
+	shardsMu.Lock()
+	shards[bot] = id
+	shardsMu.Unlock()
+}
+
+// shardBot returns bot of the shard, it's known only after the bot received a request
+func shardBot(id string) (*tgbot.TelegramBot, bool) {
+	shardsMu.RLock()
+	defer shardsMu.RUnlock()
+
+	for bot, shard := range shards {
+		if shard == id {
+			return bot, true
+		}
+	}
+
+	return nil, false
+}
+
+// shardID returns ID of the shard which received request of the bot
+func shardID(bot *tgbot.TelegramBot) string {
+	shardsMu.RLock()
+	defer shardsMu.RUnlock()
+
+	return shards[bot]
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	notified := false
+	handler := func() {
+		defer recoverPanic(func() { notified = true }, "callback", "3,5", "user_id", 1)
+
+		var game *Game
+		game.Minefield.Open(3, 5)
+	}
+
+	handler()
+	if !notified {
+		t.Error("user wasn't notified about the error")
+	}
+
+	for _, want := range []string{"Handler panicked", "callback=3,5", "user_id=1", "nil pointer dereference"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log doesn't contain %q: %s", want, logs.String())
+		}
+	}
+
+	notified = false
+	func() {
+		defer recoverPanic(func() { notified = true })
+	}()
+
+	if notified {
+		t.Error("user was notified without panic")
+	}
+}
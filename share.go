@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/floodcode/tbf"
+)
+
+// shareVersion prefixes share codes, it should be changed with their format
+const shareVersion = "ms2"
+
+// shareVersionNoReveal prefixes share codes made before min reveal option
+const shareVersionNoReveal = "ms1"
+
+// encodeShare packs the minefield and its moves into share code like
+// ms2.6.6.8.<seed>.<manual>.<flood limit>.<min reveal>.<moves>, numbers are base 36
+// and moves are separated by "-". Chorded move is followed by "+" and
+// bit mask of opened neighbors in the order they are visited, flags which
+// stopped flood of the move are listed after "!" separated by ","
+func encodeShare(m *Minefield) string {
+	manual := 0
+	if m.Manual {
+		manual = 1
+	}
+
+	moves := make([]string, 0, len(m.Moves))
+	for _, move := range m.Moves {
+		text := strconv.FormatInt(int64(move.Index), 36)
+		if len(move.Chord) > 0 {
+			text += "+" + strconv.FormatInt(m.neighborMask(move.Index, move.Chord), 36)
+		}
+
+		if len(move.Flags) > 0 {
+			flags := make([]string, 0, len(move.Flags))
+			for _, flag := range move.Flags {
+				flags = append(flags, strconv.FormatInt(int64(flag), 36))
+			}
+
+			text += "!" + strings.Join(flags, ",")
+		}
+
+		moves = append(moves, text)
+	}
+
+	fields := []string{shareVersion}
+	for _, value := range []int64{int64(m.Width), int64(m.Height), int64(m.Mines), m.Seed, int64(manual), int64(m.FloodLimit), int64(m.MinReveal)} {
+		fields = append(fields, strconv.FormatInt(value, 36))
+	}
+
+	return strings.Join(append(fields, strings.Join(moves, "-")), ".")
+}
+
+// decodeShare regenerates minefield of the share code and replays its moves,
+// every move should be valid for the board state it's made in
+func decodeShare(code string) (*Minefield, error) {
+	fields := strings.Split(strings.TrimSpace(code), ".")
+	if len(fields) == 8 && fields[0] == shareVersionNoReveal {
+		fields = slices.Insert(fields, 7, "0")
+	} else if len(fields) != 9 || fields[0] != shareVersion {
+		return nil, errors.New("unknown share code format")
+	}
+
+	var values [7]int64
+	for i := range values {
+		value, err := strconv.ParseInt(fields[i+1], 36, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %v", i+1, err)
+		}
+
+		values[i] = value
+	}
+
+	width, height, mines, seed := values[0], values[1], values[2], values[3]
+	if width < minSize || width > maxSize || height < minSize || height > maxSize {
+		return nil, fmt.Errorf("invalid size %dx%d", width, height)
+	} else if mines < minMines || mines >= width*height {
+		return nil, fmt.Errorf("invalid mines count %d", mines)
+	} else if values[5] < 0 || values[6] < 0 {
+		return nil, fmt.Errorf("invalid flood limit %d or min reveal %d", values[5], values[6])
+	}
+
+	minefield := newMinefield(int(width), int(height), int(mines), seed)
+	minefield.Manual = values[4] == 1
+	minefield.FloodLimit = int(values[5])
+	minefield.MinReveal = int(values[6])
+	if len(fields[8]) == 0 {
+		return minefield, nil
+	}
+
+	for i, text := range strings.Split(fields[8], "-") {
+		if err := minefield.applyMove(text); err != nil {
+			return nil, fmt.Errorf("move %d: %v", i+1, err)
+		}
+	}
+
+	return minefield, nil
+}
+
+// applyMove makes move encoded by encodeShare, cells listed as flags
+// are flagged only while the move is made
+func (m *Minefield) applyMove(text string) error {
+	if m.State != GameRunning {
+		return errors.New("game is already finished")
+	}
+
+	text, flagsText, hasFlags := strings.Cut(text, "!")
+	var flags []int
+	if hasFlags {
+		for _, flagText := range strings.Split(flagsText, ",") {
+			flag, err := strconv.ParseInt(flagText, 36, 32)
+			if err != nil || flag < 0 || flag >= int64(m.Width*m.Height) {
+				return fmt.Errorf("invalid flag %q", flagText)
+			}
+
+			if cell := &m.Field[flag/int64(m.Width)][flag%int64(m.Width)]; cell.closed() {
+				cell.State = StateFlagged
+				flags = append(flags, int(flag))
+			}
+		}
+	}
+
+	defer func() {
+		for _, flag := range flags {
+			m.Field[flag/m.Width][flag%m.Width].State = StateClosed
+		}
+	}()
+
+	indexText, maskText, chord := strings.Cut(text, "+")
+	index, err := strconv.ParseInt(indexText, 36, 32)
+	if err != nil || index < 0 || index >= int64(m.Width*m.Height) {
+		return fmt.Errorf("invalid cell %q", indexText)
+	}
+
+	row, col := int(index)/m.Width, int(index)%m.Width
+	if !chord {
+		if !m.Field[row][col].closed() || len(m.Open(row, col)) == 0 {
+			return fmt.Errorf("cell %d can't be opened", index)
+		}
+
+		return nil
+	}
+
+	mask, err := strconv.ParseInt(maskText, 36, 32)
+	if err != nil || m.Field[row][col].State != StateOpened {
+		return fmt.Errorf("cell %d can't be chorded", index)
+	}
+
+	var cells []int
+	valid := true
+	bit := 0
+	m.eachNeighbor(row, col, func(r, c int) {
+		if mask&(1<<bit) != 0 {
+			cells = append(cells, r*m.Width+c)
+			valid = valid && m.Field[r][c].closed()
+		}
+
+		bit++
+	})
+
+	if !valid || len(cells) == 0 || mask>>bit != 0 {
+		return fmt.Errorf("invalid neighbors of cell %d", index)
+	}
+
+	m.record(Move{Index: int(index), Chord: cells}, func() {
+		m.flood(cells)
+	})
+
+	return nil
+}
+
+// neighborMask returns bit mask of the cells among neighbors of the cell
+func (m *Minefield) neighborMask(index int, cells []int) int64 {
+	var mask int64
+	bit := 0
+	m.eachNeighbor(index/m.Width, index%m.Width, func(r, c int) {
+		if slices.Contains(cells, r*m.Width+c) {
+			mask |= 1 << bit
+		}
+
+		bit++
+	})
+
+	return mask
+}
+
+// sameBoard checks if minefields have same mines and opened cells,
+// flags aren't shared so they are ignored
+func sameBoard(a, b *Minefield) bool {
+	if a.State != b.State || a.Width != b.Width || a.Height != b.Height {
+		return false
+	}
+
+	for row := range a.Field {
+		for col, cell := range a.Field[row] {
+			other := b.Field[row][col]
+			if cell.Type != other.Type || (cell.State == StateOpened) != (other.State == StateOpened) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// shareAction sends share code of the latest finished game of the user,
+// /share <n> shares n-th latest one
+func shareAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	replay, ok := userReplay(req)
+	if !ok {
+		return
+	}
+
+	// games which can't be regenerated from their seed, like training ones,
+	// daily ones started from unrecorded cell or the ones finished by older
+	// versions, can't be shared
+	code := encodeShare(replay.Minefield)
+	if decoded, err := decodeShare(code); err != nil || !sameBoard(decoded, replay.Minefield) {
+		slog.Debug("Replay can't be shared", "user_id", req.Message.From.ID, "error", err)
+		req.QuickMessage(tr(lang, "share.unavailable"))
+		return
+	}
+
+	req.QuickMessageMD(tr(lang, "share.code", code))
+}
+
+// importAction replays game of the share code
+func importAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	args := commandArgs(req.Message.Text)
+	if len(args) != 1 {
+		req.QuickMessage(tr(lang, "import.usage"))
+		return
+	}
+
+	minefield, err := decodeShare(args[0])
+	if err != nil {
+		slog.Debug("Invalid share code", "user_id", req.Message.From.ID, "error", err)
+		req.QuickMessage(tr(lang, "import.invalid"))
+		return
+	}
+
+	sendReplay(req, Replay{
+		Minefield:  minefield,
+		Theme:      settings.Get(req.Message.From.ID).Theme,
+		FinishedAt: time.Now(),
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// playShared makes moves of every kind recorded in share codes: the first
+// tap next to a flag, hints, chords of auto flagged numbers and optionally
+// undo and a tap on a mine
+func playShared(m *Minefield, undo, lose bool) {
+	row, col := m.Height/2, m.Width/2
+	m.Flag(row, col+1)
+	m.Open(row, col)
+	m.Flag(row, col+1)
+	m.Flag(row, col+1)
+
+	for step := 0; step < 6 && m.State == GameRunning; step++ {
+		if undo && step == 3 {
+			m.Undo()
+		}
+
+		m.AutoFlag()
+		if chordAny(m) {
+			continue
+		}
+
+		if row, col, ok := m.SafeCell(); ok {
+			m.Open(row, col)
+		}
+	}
+
+	if !lose {
+		return
+	}
+
+	for index := 0; index < m.Width*m.Height; index++ {
+		if cell := m.Field[index/m.Width][index%m.Width]; cell.Type == TypeMine && cell.State != StateOpened {
+			m.Field[index/m.Width][index%m.Width].State = StateClosed
+			m.Open(index/m.Width, index%m.Width)
+			return
+		}
+	}
+}
+
+// chordAny chords the first opened number which opens something
+func chordAny(m *Minefield) bool {
+	for row := range m.Field {
+		for col := range m.Field[row] {
+			if len(m.Chord(row, col)) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func TestShareRoundTrip(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height, mines  int
+		seed                  int64
+		manual                bool
+		floodLimit, minReveal int
+		undo, lose            bool
+	}{
+		{"default", 8, 8, 10, 1, false, 0, 0, false, false},
+		{"rectangular", 5, 9, 8, 2, false, 0, 0, false, false},
+		{"manual", 6, 6, 5, 3, true, 0, 0, false, false},
+		{"flood limit", 8, 8, 6, 4, false, 3, 0, false, false},
+		{"min reveal", 8, 8, 12, 5, false, 0, 10, false, false},
+		{"undo", 8, 8, 10, 6, false, 0, 0, true, false},
+		{"lost", 8, 8, 10, 7, false, 0, 0, false, true},
+	}
+
+	for _, test := range tests {
+		for seed := test.seed; seed < test.seed+20; seed++ {
+			original := newMinefield(test.width, test.height, test.mines, seed)
+			original.Manual = test.manual
+			original.FloodLimit = test.floodLimit
+			original.MinReveal = test.minReveal
+			playShared(original, test.undo, test.lose)
+
+			code := encodeShare(original)
+			decoded, err := decodeShare(code)
+			if err != nil {
+				t.Errorf("%s, seed %d: decodeShare(%q) error = %v", test.name, seed, code, err)
+				continue
+			}
+
+			if !sameBoard(original, decoded) {
+				t.Errorf("%s, seed %d: board of %q differs from the original", test.name, seed, code)
+			}
+
+			if decoded.Manual != test.manual || decoded.FloodLimit != test.floodLimit || decoded.MinReveal != test.minReveal {
+				t.Errorf("%s, seed %d: options of %q aren't restored", test.name, seed, code)
+			}
+
+			if again := encodeShare(decoded); again != code {
+				t.Errorf("%s, seed %d: code of decoded board = %q, want %q", test.name, seed, again, code)
+			}
+
+			if test.lose && decoded.State != GameLose {
+				t.Errorf("%s, seed %d: state = %d, want loss", test.name, seed, decoded.State)
+			}
+		}
+	}
+}
+
+func TestShareOldVersion(t *testing.T) {
+	original := newMinefield(8, 8, 10, 1)
+	playShared(original, false, false)
+
+	fields := strings.Split(encodeShare(original), ".")
+	fields[0] = shareVersionNoReveal
+	fields = append(fields[:7], fields[8])
+	decoded, err := decodeShare(strings.Join(fields, "."))
+	if err != nil || !sameBoard(original, decoded) {
+		t.Errorf("decodeShare() of %s code = %v, same board %v", shareVersionNoReveal, err, err == nil && sameBoard(original, decoded))
+	}
+}
+
+func TestDecodeShareInvalid(t *testing.T) {
+	valid := encodeShare(newMinefield(8, 8, 10, 1))
+	for _, code := range []string{
+		"",
+		"ms9.8.8.a.1.0.0.0.",
+		"ms2.8.8.a.1.0.0",
+		"ms2.z.8.a.1.0.0.0.",
+		"ms2.8.8.1s.1.0.0.0.",
+		"ms2.8.8.a.1.0.-1.0.",
+		valid + "1y",
+		valid + "0+1",
+		valid + "0-0",
+	} {
+		if _, err := decodeShare(code); err == nil {
+			t.Errorf("decodeShare(%q) accepted invalid code", code)
+		}
+	}
+}
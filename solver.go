@@ -0,0 +1,320 @@
+package main
+
+import (
+	"log/slog"
+)
+
+const (
+	maxNoGuessAttempts = 200
+	maxAnalyzeSteps    = 1000000
+	probabilityEpsilon = 1e-9
+)
+
+// mineConstraint contains frontier cells around an opened number and count of mines among them
+type mineConstraint struct {
+	cells []int
+	mines int
+}
+
+// AutoFlag flags closed cells which are mines for sure, that is neighbors of
+// opened numbers having as many closed and flagged neighbors as their number.
+// Cells are never opened, count of flagged cells is returned
+func (m *Minefield) AutoFlag() int {
+	if m.State != GameRunning {
+		return 0
+	}
+
+	flagged := 0
+	for changed := true; changed; {
+		changed = false
+		for row := range m.Field {
+			for col, cell := range m.Field[row] {
+				if cell.State != StateOpened || cell.Type == TypeEmpty || cell.Type == TypeMine {
+					continue
+				}
+
+				var closed []int
+				flags := 0
+				m.eachNeighbor(row, col, func(r, c int) {
+					switch m.Field[r][c].State {
+					case StateClosed, StateQuestion:
+						closed = append(closed, r*m.Width+c)
+					case StateFlagged:
+						flags++
+					}
+				})
+
+				if len(closed) == 0 || len(closed)+flags != cell.Type-TypeEmpty {
+					continue
+				}
+
+				for _, index := range closed {
+					m.Field[index/m.Width][index%m.Width].State = StateFlagged
+				}
+
+				flagged += len(closed)
+				changed = true
+			}
+		}
+	}
+
+	return flagged
+}
+
+// newNoGuessMinefield generates minefields from consecutive seeds until one can be
+// solved by logic alone starting from the center cell, which is opened then.
+// Every attempt runs the solver over the whole board, so it takes up to a few
+// milliseconds on bigger boards, regular minefield is returned when all attempts fail
+func newNoGuessMinefield(width, height, mines int, seed int64) *Minefield {
+	row, col := height/2, width/2
+	for attempt := int64(0); attempt < maxNoGuessAttempts; attempt++ {
+		candidate := newMinefield(width, height, mines, seed+attempt)
+		candidate.Open(row, col)
+		if candidate.clone().solve() {
+			return candidate
+		}
+	}
+
+	slog.Warn("No-guess minefield not found",
+		"width", width,
+		"height", height,
+		"mines", mines,
+		"seed", seed,
+	)
+
+	return newMinefield(width, height, mines, seed)
+}
+
+// solve opens and flags cells while it can be done without guessing,
+// it reports if the game was won this way
+func (m *Minefield) solve() bool {
+	for progress := true; progress && m.State == GameRunning; {
+		progress = m.AutoFlag() > 0
+		for row := range m.Field {
+			for col, cell := range m.Field[row] {
+				if cell.State != StateOpened || cell.Type == TypeMine {
+					continue
+				}
+
+				var closed [][2]int
+				flags := 0
+				m.eachNeighbor(row, col, func(r, c int) {
+					switch m.Field[r][c].State {
+					case StateClosed, StateQuestion:
+						closed = append(closed, [2]int{r, c})
+					case StateFlagged:
+						flags++
+					}
+				})
+
+				if len(closed) == 0 || flags != cell.Type-TypeEmpty {
+					continue
+				}
+
+				for _, neighbor := range closed {
+					m.open(neighbor[0], neighbor[1])
+				}
+
+				progress = true
+			}
+		}
+	}
+
+	return m.State == GameWin
+}
+
+func (m *Minefield) clone() *Minefield {
+	clone := *m
+	clone.Field = make([][]Cell, len(m.Field))
+	for row := range m.Field {
+		clone.Field[row] = append([]Cell(nil), m.Field[row]...)
+	}
+
+	clone.History = nil
+	clone.Moves = nil
+	return &clone
+}
+
+// MineProbabilities estimates probability of a mine in every not opened cell by
+// enumerating mine layouts of cells next to opened numbers consistent with them,
+// layouts are weighted by count of ways to place the rest of mines in other cells.
+// Flags are ignored since they may be wrong, false is returned when enumeration
+// takes more than maxAnalyzeSteps steps
+func (m *Minefield) MineProbabilities() (map[int]float64, bool) {
+	var frontier, interior []int
+	position := map[int]int{}
+	var constraints []mineConstraint
+	for index := 0; index < m.Width*m.Height; index++ {
+		row, col := index/m.Width, index%m.Width
+		cell := m.Field[row][col]
+		if cell.State != StateOpened || cell.Type == TypeMine {
+			continue
+		}
+
+		var cells []int
+		m.eachNeighbor(row, col, func(r, c int) {
+			if m.Field[r][c].State == StateOpened {
+				return
+			}
+
+			neighbor := r*m.Width + c
+			if _, ok := position[neighbor]; !ok {
+				position[neighbor] = len(frontier)
+				frontier = append(frontier, neighbor)
+			}
+
+			cells = append(cells, position[neighbor])
+		})
+
+		if len(cells) > 0 {
+			constraints = append(constraints, mineConstraint{cells: cells, mines: cell.Type - TypeEmpty})
+		}
+	}
+
+	for index := 0; index < m.Width*m.Height; index++ {
+		_, isFrontier := position[index]
+		if m.Field[index/m.Width][index%m.Width].State != StateOpened && !isFrontier {
+			interior = append(interior, index)
+		}
+	}
+
+	cellConstraints := make([][]int, len(frontier))
+	left := make([]int, len(constraints))
+	assigned := make([]int, len(constraints))
+	for i, constraint := range constraints {
+		left[i] = len(constraint.cells)
+		for _, cell := range constraint.cells {
+			cellConstraints[cell] = append(cellConstraints[cell], i)
+		}
+	}
+
+	// layouts and mines of every frontier cell in them by count of mines in the layout
+	layouts := map[int]float64{}
+	cellMines := map[int][]float64{}
+	isMine := make([]bool, len(frontier))
+	steps := 0
+
+	var search func(cell, mines int) bool
+	search = func(cell, mines int) bool {
+		if steps++; steps > maxAnalyzeSteps {
+			return false
+		}
+
+		if cell == len(frontier) {
+			layouts[mines]++
+			if cellMines[mines] == nil {
+				cellMines[mines] = make([]float64, len(frontier))
+			}
+
+			for i, mine := range isMine {
+				if mine {
+					cellMines[mines][i]++
+				}
+			}
+
+			return true
+		}
+
+		for value := 0; value <= 1 && mines+value <= m.Mines; value++ {
+			valid := true
+			for _, i := range cellConstraints[cell] {
+				left[i]--
+				assigned[i] += value
+				if assigned[i] > constraints[i].mines || assigned[i]+left[i] < constraints[i].mines {
+					valid = false
+				}
+			}
+
+			isMine[cell] = value == 1
+			completed := !valid || search(cell+1, mines+value)
+			for _, i := range cellConstraints[cell] {
+				left[i]++
+				assigned[i] -= value
+			}
+
+			if !completed {
+				return false
+			}
+		}
+
+		isMine[cell] = false
+		return true
+	}
+
+	if !search(0, 0) {
+		return nil, false
+	}
+
+	probabilities := make(map[int]float64, len(frontier)+len(interior))
+	total, interiorMines := 0.0, 0.0
+	for mines, count := range layouts {
+		rest := m.Mines - mines
+		if rest < 0 || rest > len(interior) {
+			continue
+		}
+
+		ways := binomial(len(interior), rest)
+		total += count * ways
+		for i, cellCount := range cellMines[mines] {
+			probabilities[frontier[i]] += cellCount * ways
+		}
+
+		if len(interior) > 0 {
+			interiorMines += count * ways * float64(rest) / float64(len(interior))
+		}
+	}
+
+	if total == 0 {
+		return nil, false
+	}
+
+	for _, index := range frontier {
+		probabilities[index] /= total
+	}
+
+	for _, index := range interior {
+		probabilities[index] = interiorMines / total
+	}
+
+	return probabilities, true
+}
+
+// SafestCell returns not opened and not flagged cell least likely to be a mine
+// with its probability and count of such cells which are mines for sure
+func (m *Minefield) SafestCell() (row, col int, probability float64, sureMines int, ok bool) {
+	probabilities, ok := m.MineProbabilities()
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+
+	best := -1
+	for index := 0; index < m.Width*m.Height; index++ {
+		p, known := probabilities[index]
+		if !known || m.Field[index/m.Width][index%m.Width].State == StateFlagged {
+			continue
+		}
+
+		if p > 1-probabilityEpsilon {
+			sureMines++
+		}
+
+		if best < 0 || p < probabilities[best] {
+			best = index
+		}
+	}
+
+	if best < 0 {
+		return 0, 0, 0, sureMines, false
+	}
+
+	return best / m.Width, best % m.Width, probabilities[best], sureMines, true
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+
+	return result
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestAutoFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		opened      [][2]int
+		wantFlagged [][2]int
+	}{
+		{"single closed neighbor", [][2]int{{0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}}, [][2]int{{0, 0}}},
+		{"both mines", [][2]int{{0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {2, 0}, {2, 1}}, [][2]int{{0, 0}, {2, 2}}},
+		{"ambiguous", [][2]int{{1, 1}}, nil},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield("*..", "...", "..*")
+		for _, position := range test.opened {
+			minefield.Field[position[0]][position[1]].State = StateOpened
+		}
+
+		flagged := minefield.AutoFlag()
+		if flagged != len(test.wantFlagged) {
+			t.Errorf("%s: AutoFlag() = %d, want %d", test.name, flagged, len(test.wantFlagged))
+		}
+
+		for row := range minefield.Field {
+			for col, cell := range minefield.Field[row] {
+				want := slices.Contains(test.wantFlagged, [2]int{row, col})
+				if (cell.State == StateFlagged) != want {
+					t.Errorf("%s: cell %d,%d has state %d, flag expected %v", test.name, row, col, cell.State, want)
+				}
+			}
+		}
+	}
+}
+
+func TestMineProbabilities(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		open Position
+		want map[int]float64
+	}{
+		{"last cell", []string{"*.."}, Position{0, 2}, map[int]float64{0: 1}},
+		{"single number", []string{"*.", ".."}, Position{1, 1}, map[int]float64{0: 1.0 / 3, 1: 1.0 / 3, 2: 1.0 / 3}},
+		{"deduced layout", []string{"*.*", "...", "..."}, Position{2, 1}, map[int]float64{0: 1, 1: 0, 2: 1}},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield(test.rows...)
+		minefield.Open(test.open.Row, test.open.Col)
+		probabilities, ok := minefield.MineProbabilities()
+		if !ok {
+			t.Errorf("%s: MineProbabilities() gave up", test.name)
+			continue
+		}
+
+		if len(probabilities) != len(test.want) {
+			t.Errorf("%s: probabilities of %d cells, want %d", test.name, len(probabilities), len(test.want))
+		}
+
+		for index, want := range test.want {
+			if got := probabilities[index]; math.Abs(got-want) > 1e-9 {
+				t.Errorf("%s: probability of cell %d = %v, want %v", test.name, index, got, want)
+			}
+		}
+	}
+}
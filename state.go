@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	defaultSaveInterval = time.Minute
+)
+
+// BotState contains data which is kept between bot restarts
+type BotState struct {
+	Games    map[string]*Game         `json:"games"`
+	Scores   []Score                  `json:"scores"`
+	Settings map[int]UserSettings     `json:"settings"`
+	Daily    map[string][]DailyResult `json:"daily,omitempty"`
+	Chats    map[string]ChatSettings  `json:"chats,omitempty"`
+	Replays  map[int][]Replay         `json:"replays,omitempty"`
+	Outcomes map[string]Outcome       `json:"outcomes,omitempty"`
+}
+
+func loadState(path string) error {
+	stateData, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var state BotState
+	err = json.Unmarshal(stateData, &state)
+	if err != nil {
+		return err
+	}
+
+	for _, game := range state.Games {
+		if len(game.WatchID) == 0 {
+			game.WatchID = newWatchID()
+		}
+
+		games.Set(game)
+	}
+
+	scoreboard.Load(state.Scores)
+	settings.Load(state.Settings)
+	dailyResults.Load(state.Daily)
+	chatSettings.Load(state.Chats)
+	replays.Load(state.Replays)
+	outcomes.Load(state.Outcomes)
+
+	return nil
+}
+
+func saveState(path string) error {
+	stateData, err := json.Marshal(BotState{
+		Games:    games.Snapshot(),
+		Scores:   scoreboard.Snapshot(),
+		Settings: settings.Snapshot(),
+		Daily:    dailyResults.Snapshot(),
+		Chats:    chatSettings.Snapshot(),
+		Replays:  replays.Snapshot(),
+		Outcomes: outcomes.Snapshot(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, stateData, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// archiveScores saves the scores to a dated file next to the state file
+// and returns its path
+func archiveScores(scores []Score, now time.Time) (string, error) {
+	dir := "."
+	if len(botConfig.StatePath) > 0 {
+		dir = filepath.Dir(botConfig.StatePath)
+	}
+
+	scoresData, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "scores-"+now.Format("20060102-150405")+".json")
+	return path, os.WriteFile(path, scoresData, 0600)
+}
+
+func saveStatePeriodically(path string, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSaveInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := saveState(path); err != nil {
+			slog.Error("Unable to save state", "error", err)
+		}
+	}
+}
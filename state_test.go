@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveScoresAndReset(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.json")
+	withConfig(t, BotConfig{StatePath: statePath})
+	defer func(previous *Scoreboard) { scoreboard = previous }(scoreboard)
+	scoreboard = newScoreboard()
+	scoreboard.AddWin(1, "Alice", testDifficulty, time.Minute)
+	scoreboard.AddLoss(2, "Bob")
+
+	now := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	path, err := archiveScores(scoreboard.Snapshot(), now)
+	if err != nil {
+		t.Fatalf("archiveScores() error = %v", err)
+	}
+
+	if want := filepath.Join(dir, "scores-20240301-123000.json"); path != want {
+		t.Errorf("archive path = %q, want %q", path, want)
+	}
+
+	scoreboard.Reset()
+	if err := saveState(statePath); err != nil {
+		t.Fatalf("saveState() error = %v", err)
+	}
+
+	var archived []Score
+	if err := readJSON(path, &archived); err != nil || len(archived) != 2 {
+		t.Errorf("archive has %d scores, error %v, want 2", len(archived), err)
+	}
+
+	var state BotState
+	if err := readJSON(statePath, &state); err != nil || len(state.Scores) != 0 {
+		t.Errorf("state has %d scores, error %v, want 0", len(state.Scores), err)
+	}
+
+	if _, err := archiveScores(nil, now.Add(time.Second)); err != nil {
+		t.Fatalf("second archiveScores() error = %v", err)
+	}
+
+	if err := readJSON(path, &archived); err != nil || len(archived) != 2 {
+		t.Errorf("first archive has %d scores after the second reset, error %v", len(archived), err)
+	}
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// GameStore contains active games and can be safely used from multiple goroutines
+type GameStore struct {
+	mu    sync.RWMutex
+	games map[string]*Game
+	chats map[string]string
+	last  map[string]Difficulty
+
+	finished map[string]*Game
+}
+
+func newGameStore() *GameStore {
+	return &GameStore{
+		games: map[string]*Game{},
+		chats: map[string]string{},
+		last:  map[string]Difficulty{},
+
+		finished: map[string]*Game{},
+	}
+}
+
+// Get returns game by its key
+func (s *GameStore) Get(key string) (*Game, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	game, ok := s.games[key]
+	return game, ok
+}
+
+// GetByWatchID returns game by ID used in its watch link
+func (s *GameStore) GetByWatchID(watchID string) (*Game, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, game := range s.games {
+		if game.WatchID == watchID {
+			return game, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetByChat returns latest game started in the chat of the shard, when it's
+// removed the most recent of the remaining games of the chat is returned
+func (s *GameStore) GetByChat(shard string, chatID int) (*Game, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.chats[chatKey(shard, chatID)]
+	if !ok {
+		return nil, false
+	}
+
+	game, ok := s.games[key]
+	return game, ok
+}
+
+// Set stores game by its key and makes it latest game in its chat
+func (s *GameStore) Set(game *Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := game.Key()
+	s.games[key] = game
+	if game.ChatID != 0 {
+		s.chats[chatKey(game.Shard, game.ChatID)] = key
+		s.last[chatKey(game.Shard, game.ChatID)] = game.Minefield.Difficulty()
+	}
+}
+
+// Count returns count of active games
+func (s *GameStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.games)
+}
+
+// Chats returns IDs of chats of the shard having active games
+func (s *GameStore) Chats(shard string) []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := map[int]bool{}
+	var chats []int
+	for _, game := range s.games {
+		if game.ChatID != 0 && game.Shard == shard && !seen[game.ChatID] {
+			seen[game.ChatID] = true
+			chats = append(chats, game.ChatID)
+		}
+	}
+
+	return chats
+}
+
+// ByChat returns active games of the chat of the shard ordered by creation time
+func (s *GameStore) ByChat(shard string, chatID int) []*Game {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var chatGames []*Game
+	for _, game := range s.games {
+		if game.ChatID == chatID && game.Shard == shard {
+			chatGames = append(chatGames, game)
+		}
+	}
+
+	sort.Slice(chatGames, func(i, j int) bool {
+		return chatGames[i].CreatedAt.Before(chatGames[j].CreatedAt)
+	})
+
+	return chatGames
+}
+
+// CountByOwner returns count of active games started by the user
+func (s *GameStore) CountByOwner(ownerID int) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, game := range s.games {
+		if game.OwnerID == ownerID {
+			count++
+		}
+	}
+
+	return count
+}
+
+// DeleteByOwner removes all games started by the user and returns count of removed games
+func (s *GameStore) DeleteByOwner(ownerID int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for key, game := range s.games {
+		if game.OwnerID == ownerID {
+			s.delete(key)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+// GetLast returns parameters of the latest game started in the chat,
+// they are kept after the game itself is removed
+func (s *GameStore) GetLast(shard string, chatID int) (Difficulty, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	difficulty, ok := s.last[chatKey(shard, chatID)]
+	return difficulty, ok
+}
+
+// Delete removes game by its key
+func (s *GameStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delete(key)
+}
+
+// Finish removes finished game and keeps it as the last finished game in its chat,
+// inline games are kept by their board until they're evicted
+func (s *GameStore) Finish(game *Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delete(game.Key())
+	if len(game.InlineMessageID) > 0 {
+		s.finished[game.Key()] = game
+	} else if game.ChatID != 0 {
+		s.finished[chatKey(game.Shard, game.ChatID)] = game
+	}
+}
+
+// GetFinished returns last finished game of the chat of the shard
+func (s *GameStore) GetFinished(shard string, chatID int) (*Game, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	game, ok := s.finished[chatKey(shard, chatID)]
+	return game, ok
+}
+
+// GetFinishedInline returns finished game of the inline board
+func (s *GameStore) GetFinishedInline(inlineMessageID string) (*Game, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	game, ok := s.finished[inlineKey(inlineMessageID)]
+	return game, ok
+}
+
+// Evict removes games created before the deadline and returns count of removed
+// active games, finished inline games are removed as well
+func (s *GameStore) Evict(deadline time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted := 0
+	for key, game := range s.games {
+		if game.CreatedAt.Before(deadline) {
+			s.delete(key)
+			evicted++
+		}
+	}
+
+	for key, game := range s.finished {
+		if len(game.InlineMessageID) > 0 && game.CreatedAt.Before(deadline) {
+			delete(s.finished, key)
+		}
+	}
+
+	return evicted
+}
+
+// Idle returns games without moves since the deadline, last activity of each
+// game is read under its lock taken after the store lock is released since
+// moves lock the game first and then the store
+func (s *GameStore) Idle(deadline time.Time) []*Game {
+	var idle []*Game
+	for _, game := range s.Snapshot() {
+		game.mu.Lock()
+		lastActivity := game.LastActivity()
+		game.mu.Unlock()
+
+		if lastActivity.Before(deadline) {
+			idle = append(idle, game)
+		}
+	}
+
+	return idle
+}
+
+// Snapshot returns copy of the key to game mapping
+func (s *GameStore) Snapshot() map[string]*Game {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[string]*Game, len(s.games))
+	for key, game := range s.games {
+		snapshot[key] = game
+	}
+
+	return snapshot
+}
+
+func (s *GameStore) delete(key string) {
+	game, ok := s.games[key]
+	if !ok {
+		return
+	}
+
+	delete(s.games, key)
+	chat := chatKey(game.Shard, game.ChatID)
+	if s.chats[chat] != key {
+		return
+	}
+
+	// the most recent remaining game of the chat becomes its latest one
+	var latest *Game
+	for _, other := range s.games {
+		if other.ChatID == game.ChatID && other.Shard == game.Shard && (latest == nil || other.CreatedAt.After(latest.CreatedAt)) {
+			latest = other
+		}
+	}
+
+	if latest == nil {
+		delete(s.chats, chat)
+	} else {
+		s.chats[chat] = latest.Key()
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testGame(ownerID, chatID, messageID int) *Game {
+	return &Game{
+		Minefield: newMinefield(8, 8, 10, int64(messageID)),
+		OwnerID:   ownerID,
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+}
+
+func TestGameStoreConcurrentGames(t *testing.T) {
+	store := newGameStore()
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			game := testGame(i, i, i)
+			store.Set(game)
+			if stored, ok := store.Get(game.Key()); !ok || stored != game {
+				t.Errorf("game %d not found", i)
+			}
+
+			if stored, ok := store.GetByChat("", i); !ok || stored != game {
+				t.Errorf("game of chat %d not found", i)
+			}
+
+			if i%2 == 0 {
+				store.Delete(game.Key())
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	for i := 1; i <= 50; i++ {
+		_, ok := store.Get(messageKey(i, i))
+		if want := i%2 != 0; ok != want {
+			t.Errorf("game %d stored = %t, want %t", i, ok, want)
+		}
+	}
+}
+
+func TestGameStoreDelete(t *testing.T) {
+	store := newGameStore()
+	game := testGame(1, 10, 100)
+	store.Set(game)
+	store.Delete(game.Key())
+
+	if _, ok := store.Get(game.Key()); ok {
+		t.Error("finished game is still active")
+	}
+
+	if _, ok := store.GetByChat("", 10); ok {
+		t.Error("finished game is still the latest one of the chat")
+	}
+}
+
+func TestGameStoreGetByChatFallback(t *testing.T) {
+	store := newGameStore()
+	now := time.Now()
+	older, newer, latest := testGame(1, 10, 100), testGame(1, 10, 101), testGame(1, 10, 102)
+	older.CreatedAt = now.Add(-time.Hour)
+	newer.CreatedAt = now.Add(-time.Minute)
+	latest.CreatedAt = now
+	other := testGame(1, 11, 103)
+	other.CreatedAt = now.Add(time.Minute)
+	for _, game := range []*Game{newer, older, other, latest} {
+		store.Set(game)
+	}
+
+	for _, want := range []*Game{latest, newer, older, nil} {
+		game, ok := store.GetByChat("", 10)
+		if want == nil {
+			if ok {
+				t.Errorf("GetByChat() = game %d, want none", game.MessageID)
+			}
+
+			break
+		}
+
+		if !ok || game != want {
+			t.Fatalf("GetByChat() = %v, %v, want game %d", game, ok, want.MessageID)
+		}
+
+		store.Delete(game.Key())
+	}
+}
+
+func TestGameStoreEvict(t *testing.T) {
+	store := newGameStore()
+	now := time.Now()
+	old, fresh := testGame(1, 10, 100), testGame(2, 10, 101)
+	old.CreatedAt = now.Add(-2 * time.Hour)
+	fresh.CreatedAt = now
+	store.Set(old)
+	store.Set(fresh)
+
+	if evicted := store.Evict(now.Add(-time.Hour)); evicted != 1 {
+		t.Errorf("Evict() = %d, want 1", evicted)
+	}
+
+	tests := []struct {
+		game   *Game
+		active bool
+	}{
+		{old, false},
+		{fresh, true},
+	}
+
+	for _, test := range tests {
+		if _, ok := store.Get(test.game.Key()); ok != test.active {
+			t.Errorf("game %s active = %t, want %t", test.game.Key(), ok, test.active)
+		}
+	}
+}
+
+func TestGameStoreDeleteByOwner(t *testing.T) {
+	store := newGameStore()
+	for i, ownerID := range []int{1, 2, 1, 1, 3} {
+		store.Set(testGame(ownerID, 10+i%2, 100+i))
+	}
+
+	if deleted := store.DeleteByOwner(1); deleted != 3 {
+		t.Errorf("DeleteByOwner(1) = %d, want 3", deleted)
+	}
+
+	if count := store.CountByOwner(1); count != 0 {
+		t.Errorf("%d games of the owner left, want 0", count)
+	}
+
+	if count := store.Count(); count != 2 {
+		t.Errorf("%d games left, want 2 games of other users", count)
+	}
+
+	if deleted := store.DeleteByOwner(1); deleted != 0 {
+		t.Errorf("second DeleteByOwner(1) = %d, want 0", deleted)
+	}
+}
+
+func TestGameStoreIdle(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		createdAt  time.Time
+		lastMoveAt time.Time
+		wantIdle   bool
+	}{
+		{"old move", now.Add(-2 * time.Hour), now.Add(-90 * time.Minute), true},
+		{"recent move", now.Add(-2 * time.Hour), now.Add(-time.Minute), false},
+		{"old game without moves", now.Add(-2 * time.Hour), time.Time{}, true},
+		{"new game without moves", now.Add(-time.Minute), time.Time{}, false},
+	}
+
+	store := newGameStore()
+	for i, test := range tests {
+		game := testGame(1, 10, 100+i)
+		game.CreatedAt, game.LastMoveAt = test.createdAt, test.lastMoveAt
+		store.Set(game)
+	}
+
+	idle := store.Idle(now.Add(-time.Hour))
+	for i, test := range tests {
+		game, _ := store.Get(messageKey(10, 100+i))
+		if got := slices.Contains(idle, game); got != test.wantIdle {
+			t.Errorf("%s: idle %v, want %v", test.name, got, test.wantIdle)
+		}
+	}
+}
+
+func TestGameStoreByChat(t *testing.T) {
+	store := newGameStore()
+	now := time.Now()
+	games := []struct {
+		shard     string
+		chatID    int
+		messageID int
+		age       time.Duration
+	}{
+		{"", 10, 100, time.Minute},
+		{"", 10, 101, time.Hour},
+		{"", 11, 102, time.Minute},
+		{"second", 10, 103, time.Minute},
+		{"", 10, 104, time.Second},
+	}
+
+	for _, g := range games {
+		game := testGame(1, g.chatID, g.messageID)
+		game.Shard, game.CreatedAt = g.shard, now.Add(-g.age)
+		store.Set(game)
+	}
+
+	finished := testGame(1, 10, 105)
+	store.Set(finished)
+	store.Finish(finished)
+
+	var messageIDs []int
+	for _, game := range store.ByChat("", 10) {
+		messageIDs = append(messageIDs, game.MessageID)
+	}
+
+	if want := []int{101, 100, 104}; !slices.Equal(messageIDs, want) {
+		t.Errorf("ByChat() returned boards %v, want %v", messageIDs, want)
+	}
+}
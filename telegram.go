@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	maxEditAttempts = 4
+	editRetryDelay  = 500 * time.Millisecond
+)
+
+var (
+	retryAfterRe = regexp.MustCompile(`retry after (\d+)`)
+
+	// retrySleep pauses between retries, replaced in tests
+	retrySleep = time.Sleep
+
+	// sendEdit sends message edit to Telegram, replaced in tests
+	sendEdit = func(bot *tgbot.TelegramBot, config tgbot.EditMessageTextConfig) error {
+		_, err := bot.EditMessageText(config)
+		return err
+	}
+
+	// chatMemberStatus returns status of the user in the chat like "administrator",
+	// replaced in tests
+	chatMemberStatus = func(bot *tgbot.TelegramBot, chatID, userID int) (string, error) {
+		member, err := bot.GetChatMember(tgbot.GetChatMemberConfig{
+			ChatID: tgbot.ChatID(chatID),
+			UserID: userID,
+		})
+
+		return member.Status, err
+	}
+
+	transientErrors = []string{
+		"too many requests",
+		"internal server error",
+		"bad gateway",
+		"gateway timeout",
+	}
+)
+
+// editMessage edits message ignoring "message is not modified" errors,
+// transient errors are retried with exponential backoff
+func editMessage(bot *tgbot.TelegramBot, config tgbot.EditMessageTextConfig) error {
+	return retryEdit(func() error {
+		return sendEdit(bot, config)
+	})
+}
+
+// retryEdit calls edit until it succeeds, fails with non-transient error
+// or runs out of attempts
+func retryEdit(edit func() error) error {
+	delay := editRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := edit()
+		if err == nil || isNotModifiedError(err) {
+			return nil
+		}
+
+		apiErrors.Inc()
+
+		if attempt == maxEditAttempts || !isTransientError(err) {
+			return err
+		}
+
+		if retryAfter, ok := parseRetryAfter(err); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+
+		retrySleep(delay)
+		delay *= 2
+	}
+}
+
+// sendMessage sends message retrying once on transient errors
+func sendMessage(bot *tgbot.TelegramBot, config tgbot.SendMessageConfig) (tgbot.Message, error) {
+	return retrySend(func() (tgbot.Message, error) {
+		return bot.SendMessage(config)
+	})
+}
+
+// retrySend calls send again after a pause when it fails with transient error
+func retrySend(send func() (tgbot.Message, error)) (tgbot.Message, error) {
+	msg, err := send()
+	if err == nil || !isTransientError(err) {
+		return msg, err
+	}
+
+	apiErrors.Inc()
+	delay := editRetryDelay
+	if retryAfter, ok := parseRetryAfter(err); ok && retryAfter > delay {
+		delay = retryAfter
+	}
+
+	retrySleep(delay)
+	return send()
+}
+
+func isNotModifiedError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "message is not modified")
+}
+
+// isMessageNotFoundError checks if edited message doesn't exist anymore,
+// e.g. it was deleted by the user
+func isMessageNotFoundError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "message to edit not found")
+}
+
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	text := strings.ToLower(err.Error())
+	for _, transient := range transientErrors {
+		if strings.Contains(text, transient) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseRetryAfter(err error) (time.Duration, bool) {
+	match := retryAfterRe.FindStringSubmatch(strings.ToLower(err.Error()))
+	if match == nil {
+		return 0, false
+	}
+
+	seconds, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/floodcode/tgbot"
+)
+
+// withRetrySleep records pauses between retries instead of sleeping
+func withRetrySleep(t *testing.T) *[]time.Duration {
+	var slept []time.Duration
+	previous := retrySleep
+	retrySleep = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+
+	t.Cleanup(func() {
+		retrySleep = previous
+	})
+
+	return &slept
+}
+
+func TestRetryEdit(t *testing.T) {
+	tooManyRequests := errors.New("Too Many Requests: retry after 2")
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   bool
+		wantCalls int
+		wantSlept []time.Duration
+	}{
+		{"success", []error{nil}, false, 1, nil},
+		{"not modified", []error{errors.New("Bad Request: message is not modified")}, false, 1, nil},
+		{"rate limited", []error{tooManyRequests, nil}, false, 2, []time.Duration{2 * time.Second}},
+		{"server error", []error{errors.New("Bad Gateway"), errors.New("Bad Gateway"), nil}, false, 3,
+			[]time.Duration{editRetryDelay, 2 * editRetryDelay}},
+		{"permanent error", []error{errors.New("Bad Request: chat not found")}, true, 1, nil},
+		{"attempts exhausted", []error{tooManyRequests, tooManyRequests, tooManyRequests, tooManyRequests}, true,
+			maxEditAttempts, []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}},
+	}
+
+	for _, test := range tests {
+		slept := withRetrySleep(t)
+		calls := 0
+		err := retryEdit(func() error {
+			calls++
+			return test.errs[calls-1]
+		})
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: retryEdit() error = %v, want error %v", test.name, err, test.wantErr)
+		}
+
+		if calls != test.wantCalls {
+			t.Errorf("%s: edit called %d times, want %d", test.name, calls, test.wantCalls)
+		}
+
+		if !slices.Equal(*slept, test.wantSlept) {
+			t.Errorf("%s: slept %v, want %v", test.name, *slept, test.wantSlept)
+		}
+	}
+}
+
+// withSendEdit counts board edits instead of sending them to Telegram
+func withSendEdit(t *testing.T) *int {
+	var mu sync.Mutex
+	edits := 0
+	previous := sendEdit
+	sendEdit = func(*tgbot.TelegramBot, tgbot.EditMessageTextConfig) error {
+		mu.Lock()
+		defer mu.Unlock()
+		edits++
+		return nil
+	}
+
+	t.Cleanup(func() {
+		sendEdit = previous
+	})
+
+	return &edits
+}
+
+func TestRetrySend(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   bool
+		wantCalls int
+	}{
+		{"success", []error{nil}, false, 1},
+		{"transient error", []error{errors.New("Internal Server Error"), nil}, false, 2},
+		{"transient errors", []error{errors.New("Bad Gateway"), errors.New("Bad Gateway")}, true, 2},
+		{"permanent error", []error{errors.New("Forbidden: bot was blocked by the user")}, true, 1},
+	}
+
+	for _, test := range tests {
+		withRetrySleep(t)
+		calls := 0
+		msg, err := retrySend(func() (tgbot.Message, error) {
+			calls++
+			if err := test.errs[calls-1]; err != nil {
+				return tgbot.Message{}, err
+			}
+
+			return tgbot.Message{MessageID: 100}, nil
+		})
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: retrySend() error = %v, want error %v", test.name, err, test.wantErr)
+		}
+
+		if !test.wantErr && msg.MessageID != 100 {
+			t.Errorf("%s: message ID = %d, want 100", test.name, msg.MessageID)
+		}
+
+		if calls != test.wantCalls {
+			t.Errorf("%s: send called %d times, want %d", test.name, calls, test.wantCalls)
+		}
+	}
+}
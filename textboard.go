@@ -0,0 +1,99 @@
+package main
+
+import (
+	"html"
+	"log/slog"
+	"strings"
+
+	"github.com/floodcode/tgbot"
+)
+
+const parseModeHTML = "HTML"
+
+// renderHTMLBoard renders read-only copy of the board as HTML message text,
+// it's posted next to the keyboard when text_board is enabled
+func renderHTMLBoard(text string, minefield *Minefield) string {
+	lines := make([]string, 0, minefield.Height)
+	for _, cells := range minefield.Field {
+		var line strings.Builder
+		for _, cell := range cells {
+			line.WriteString(renderHTMLCell(cell, minefield.State))
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	return html.EscapeString(text) + "\n\n" + strings.Join(lines, "\n")
+}
+
+// renderHTMLCell renders grid cell as monospaced text, Telegram doesn't
+// support text colors so numbers and mines are made bold instead
+func renderHTMLCell(cell Cell, gameState int) string {
+	char := renderGridCell(cell, gameState)
+	code := "<code>" + html.EscapeString(char+" ") + "</code>"
+	if cell.State == StateOpened && cell.Type != TypeEmpty || char == "*" {
+		return "<b>" + code + "</b>"
+	}
+
+	return code
+}
+
+// sendTextBoard posts HTML copy of the board into the game chat,
+// game lock should be held
+func sendTextBoard(bot *tgbot.TelegramBot, game *Game, text string) {
+	if !botConfig.TextBoard || len(game.InlineMessageID) > 0 {
+		return
+	}
+
+	msg, err := sendMessage(bot, tgbot.SendMessageConfig{
+		ChatID:    tgbot.ChatID(game.ChatID),
+		Text:      renderHTMLBoard(text, game.Minefield),
+		ParseMode: parseModeHTML,
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send text board", "game", game.Key(), "error", err)
+		return
+	}
+
+	game.TextMessageID = msg.MessageID
+}
+
+// updateTextBoard edits HTML copy of the board, game lock should be held
+func updateTextBoard(bot *tgbot.TelegramBot, game *Game, text string) {
+	if game.TextMessageID == 0 {
+		return
+	}
+
+	err := editMessage(bot, tgbot.EditMessageTextConfig{
+		ChatID:    tgbot.ChatID(game.ChatID),
+		MessageID: game.TextMessageID,
+		Text:      renderHTMLBoard(text, game.Minefield),
+		ParseMode: parseModeHTML,
+	})
+
+	if err != nil {
+		slog.Error("Unable to update text board", "game", game.Key(), "error", err)
+	}
+}
+
+// moveTextBoard posts HTML copy of the board again after the board itself was
+// re-sent by /board, the old copy is replaced with a note, game lock should be held
+func moveTextBoard(bot *tgbot.TelegramBot, game *Game, text string) {
+	oldMessageID := game.TextMessageID
+	sendTextBoard(bot, game, text)
+	if oldMessageID == 0 || game.TextMessageID == oldMessageID {
+		return
+	}
+
+	err := editMessage(bot, tgbot.EditMessageTextConfig{
+		ChatID:    tgbot.ChatID(game.ChatID),
+		MessageID: oldMessageID,
+		Text:      tr(game.Language, "game.moved"),
+	})
+
+	if err != nil {
+		slog.Error("Unable to update text board", "game", game.Key(), "error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestRenderHTMLBoard(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		rows  []string
+		state int
+		open  [][2]int
+		flag  [][2]int
+		want  string
+	}{
+		{
+			name:  "escaped title",
+			title: `<b>Tom & "Jerry"</b>`,
+			rows:  []string{"*."},
+			state: GameRunning,
+			want:  "&lt;b&gt;Tom &amp; &#34;Jerry&#34;&lt;/b&gt;\n\n<code>. </code><code>. </code>",
+		},
+		{
+			name:  "bold numbers",
+			title: "Game",
+			rows:  []string{"*..", "..."},
+			state: GameRunning,
+			open:  [][2]int{{0, 1}, {1, 2}},
+			flag:  [][2]int{{0, 0}},
+			want:  "Game\n\n<code>F </code><b><code>1 </code></b><code>. </code>\n<code>. </code><code>. </code><code>  </code>",
+		},
+		{
+			name:  "bold mines of finished game",
+			title: "Game",
+			rows:  []string{"*."},
+			state: GameLose,
+			want:  "Game\n\n<b><code>* </code></b><code>. </code>",
+		},
+	}
+
+	for _, test := range tests {
+		minefield := testMinefield(test.rows...)
+		for _, cell := range test.open {
+			minefield.Field[cell[0]][cell[1]].State = StateOpened
+		}
+
+		for _, cell := range test.flag {
+			minefield.Field[cell[0]][cell[1]].State = StateFlagged
+		}
+
+		minefield.State = test.state
+		if got := renderHTMLBoard(test.title, minefield); got != test.want {
+			t.Errorf("%s: renderHTMLBoard() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
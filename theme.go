@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sort"
+)
+
+const (
+	defaultTheme = "classic"
+)
+
+var (
+	themes = map[string]Theme{
+		"classic": {
+			Closed:   "⬜️",
+			Flagged:  "ℹ️",
+			Question: "❓",
+			Exploded: "💥",
+			Types: map[int]string{
+				TypeEmpty: " ",
+				Type1:     "1️⃣",
+				Type2:     "2️⃣",
+				Type3:     "3️⃣",
+				Type4:     "4️⃣",
+				Type5:     "5️⃣",
+				Type6:     "6️⃣",
+				Type7:     "7️⃣",
+				Type8:     "8️⃣",
+				TypeMine:  "⚫️",
+			},
+		},
+		"dark": {
+			Closed:   "⬛️",
+			Flagged:  "🚩",
+			Question: "❓",
+			Exploded: "💥",
+			Types: map[int]string{
+				TypeEmpty: " ",
+				Type1:     "1️⃣",
+				Type2:     "2️⃣",
+				Type3:     "3️⃣",
+				Type4:     "4️⃣",
+				Type5:     "5️⃣",
+				Type6:     "6️⃣",
+				Type7:     "7️⃣",
+				Type8:     "8️⃣",
+				TypeMine:  "💣",
+			},
+		},
+		"minimal": {
+			Closed:   "■",
+			Flagged:  "⚑",
+			Question: "?",
+			Exploded: "✱",
+			Types: map[int]string{
+				TypeEmpty: "·",
+				Type1:     "1",
+				Type2:     "2",
+				Type3:     "3",
+				Type4:     "4",
+				Type5:     "5",
+				Type6:     "6",
+				Type7:     "7",
+				Type8:     "8",
+				TypeMine:  "*",
+			},
+		},
+	}
+)
+
+// Theme contains glyphs used to render minefield cells
+type Theme struct {
+	Closed   string
+	Flagged  string
+	Question string
+	Exploded string
+	Types    map[int]string
+}
+
+func getTheme(name string) Theme {
+	if theme, ok := themes[name]; ok {
+		return theme
+	}
+
+	return themes[defaultTheme]
+}
+
+func getThemeName(name string) string {
+	if _, ok := themes[name]; ok {
+		return name
+	}
+
+	return defaultTheme
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
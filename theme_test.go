@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestThemeChangesOnlyPresentation(t *testing.T) {
+	game := &Game{Minefield: testMinefield("..*..", "..*..", ".....", "*...*"), Theme: defaultTheme}
+	game.Minefield.Open(0, 0)
+	game.Minefield.Flag(3, 4)
+	states := fieldStates(game.Minefield)
+	before := renderCells(game, false)
+
+	for _, name := range themeNames() {
+		if name == defaultTheme {
+			continue
+		}
+
+		game.Theme = name
+		after := renderCells(game, false)
+		changed := false
+		for row := range after {
+			for col := range after[row] {
+				if after[row][col].CallbackData != before[row][col].CallbackData {
+					t.Errorf("%s: button %d,%d taps %q, want %q", name, row, col, after[row][col].CallbackData, before[row][col].CallbackData)
+				}
+
+				changed = changed || after[row][col].Text != before[row][col].Text
+			}
+		}
+
+		if !changed {
+			t.Errorf("%s: board looks the same as with %s theme", name, defaultTheme)
+		}
+
+		if !slices.Equal(fieldStates(game.Minefield), states) || game.Minefield.State != GameRunning {
+			t.Errorf("%s: cells changed with the theme", name)
+		}
+	}
+}
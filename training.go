@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/floodcode/tbf"
+)
+
+// trainingPatterns contains hand-authored boards focused on a single deduction,
+// * is a mine, o is an opened safe cell and . is a closed safe cell
+var trainingPatterns = map[string][]string{
+	"11": {
+		"*..*",
+		"oooo",
+		"oooo",
+	},
+	"121": {
+		".*.*.",
+		"ooooo",
+		"ooooo",
+	},
+	"1221": {
+		".**.",
+		"oooo",
+		"oooo",
+	},
+	"5050": {
+		"*ooo",
+		".ooo",
+	},
+}
+
+// newTrainingMinefield creates minefield from the pattern layout
+func newTrainingMinefield(layout []string) *Minefield {
+	minefield := &Minefield{
+		Width:  len(layout[0]),
+		Height: len(layout),
+		State:  GameRunning,
+		Field:  make([][]Cell, len(layout)),
+	}
+
+	for row, line := range layout {
+		minefield.Field[row] = make([]Cell, len(line))
+		for col, char := range line {
+			switch char {
+			case '*':
+				minefield.Field[row][col].Type = TypeMine
+				minefield.Mines++
+			case 'o':
+				minefield.Field[row][col].State = StateOpened
+			}
+		}
+	}
+
+	minefield.countNeighbors()
+	return minefield
+}
+
+// trainAction starts practice game with the pattern or lists available patterns
+func trainAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	args := commandArgs(req.Message.Text)
+	if len(args) == 0 {
+		lines := []string{tr(lang, "train.list")}
+		for _, name := range trainingNames() {
+			lines = append(lines, "`"+name+"` — "+tr(lang, "train.pattern_"+name))
+		}
+
+		req.QuickMessageMD(strings.Join(lines, "\n"))
+		return
+	}
+
+	layout, ok := trainingPatterns[strings.ToLower(args[0])]
+	if !ok {
+		req.QuickMessageMD(tr(lang, "train.unknown", strings.Join(trainingNames(), ", ")))
+		return
+	}
+
+	if gameLimitReached(req) {
+		return
+	}
+
+	game := newGame(req.Message.From, newTrainingMinefield(layout))
+	game.Training = strings.ToLower(args[0])
+	startGame(req.Bot, req.Message.Chat.ID, game)
+}
+
+func trainingNames() []string {
+	names := make([]string, 0, len(trainingPatterns))
+	for name := range trainingPatterns {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrainingPatterns(t *testing.T) {
+	for _, name := range trainingNames() {
+		layout := trainingPatterns[name]
+		minefield := newTrainingMinefield(layout)
+		if minefield.Width != len(layout[0]) || minefield.Height != len(layout) {
+			t.Errorf("%s: board is %dx%d, want %dx%d", name, minefield.Width, minefield.Height, len(layout[0]), len(layout))
+		}
+
+		mines, opened := 0, 0
+		for row, line := range layout {
+			if len(line) != minefield.Width {
+				t.Errorf("%s: row %d has %d cells, want %d", name, row, len(line), minefield.Width)
+			}
+
+			mines += strings.Count(line, "*")
+			opened += strings.Count(line, "o")
+		}
+
+		if minefield.Mines != mines || minefield.countState(StateOpened) != opened {
+			t.Errorf("%s: %d mines and %d opened cells, want %d and %d",
+				name, minefield.Mines, minefield.countState(StateOpened), mines, opened)
+		}
+
+		if minefield.State != GameRunning || minefield.countState(StateClosed) == mines {
+			t.Errorf("%s: nothing left to solve", name)
+		}
+
+		for lang := range translations {
+			if _, ok := translations[lang]["train.pattern_"+name]; !ok {
+				t.Errorf("%s: no %s description", name, lang)
+			}
+		}
+	}
+}
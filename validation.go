@@ -0,0 +1,169 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+)
+
+// localizedError is implemented by errors which should be shown to users,
+// Localize describes the error in the language
+type localizedError interface {
+	error
+	Localize(lang string) string
+}
+
+// errorText describes the error in the language when it supports localization
+func errorText(lang string, err error) string {
+	if localized, ok := err.(localizedError); ok {
+		return localized.Localize(lang)
+	}
+
+	return err.Error()
+}
+
+// SeedParseError is returned when seed of the board isn't a number
+type SeedParseError struct {
+	Err error
+}
+
+func (e SeedParseError) Error() string { return e.Localize(defaultLanguage) }
+
+func (e SeedParseError) Unwrap() error { return e.Err }
+
+// Localize describes the error in the language
+func (e SeedParseError) Localize(lang string) string {
+	return tr(lang, "error.seed")
+}
+
+// SizeParseError is returned when width or height of the board isn't a number,
+// Dimension contains i18n key of its name
+type SizeParseError struct {
+	Dimension string
+	Err       error
+}
+
+func (e SizeParseError) Error() string { return e.Localize(defaultLanguage) }
+
+func (e SizeParseError) Unwrap() error { return e.Err }
+
+// Localize describes the error in the language
+func (e SizeParseError) Localize(lang string) string {
+	return tr(lang, "error.size_number", tr(lang, e.Dimension))
+}
+
+// WidthRangeError is returned when width of the board is out of bounds
+type WidthRangeError struct {
+	Width, Min, Max int64
+}
+
+func (e WidthRangeError) Error() string { return e.Localize(defaultLanguage) }
+
+// Localize describes the error in the language
+func (e WidthRangeError) Localize(lang string) string {
+	return tr(lang, "error.size_range", tr(lang, "error.width"), e.Min, e.Max)
+}
+
+// HeightRangeError is returned when height of the board is out of bounds
+type HeightRangeError struct {
+	Height, Min, Max int64
+}
+
+func (e HeightRangeError) Error() string { return e.Localize(defaultLanguage) }
+
+// Localize describes the error in the language
+func (e HeightRangeError) Localize(lang string) string {
+	return tr(lang, "error.size_range", tr(lang, "error.height"), e.Min, e.Max)
+}
+
+// KeyboardError is returned when the board can't be shown by inline keyboard
+type KeyboardError struct {
+	Width, Height int64
+}
+
+func (e KeyboardError) Error() string { return e.Localize(defaultLanguage) }
+
+// Localize describes the error in the language
+func (e KeyboardError) Localize(lang string) string {
+	return tr(lang, "error.keyboard_size")
+}
+
+// MinesParseError is returned when mines count isn't a number
+type MinesParseError struct {
+	Err error
+}
+
+func (e MinesParseError) Error() string { return e.Localize(defaultLanguage) }
+
+func (e MinesParseError) Unwrap() error { return e.Err }
+
+// Localize describes the error in the language
+func (e MinesParseError) Localize(lang string) string {
+	return tr(lang, "error.mines_number")
+}
+
+// MinesRangeError is returned when mines count is out of bounds for the board
+type MinesRangeError struct {
+	Width, Height, Mines, Min, Max int64
+}
+
+func (e MinesRangeError) Error() string { return e.Localize(defaultLanguage) }
+
+// Localize describes the error in the language
+func (e MinesRangeError) Localize(lang string) string {
+	if e.Mines < e.Min {
+		return tr(lang, "error.mines_min", e.Min)
+	}
+
+	return tr(lang, "error.mines_max", e.Width, e.Height, e.Max, e.Mines)
+}
+
+func validateWidth(width int64, err error) error {
+	if err != nil {
+		return SizeParseError{Dimension: "error.width", Err: err}
+	}
+
+	if width < minSize || width > maxSize {
+		return WidthRangeError{Width: width, Min: minSize, Max: maxSize}
+	}
+
+	return nil
+}
+
+func validateHeight(height int64, err error) error {
+	if err != nil {
+		return SizeParseError{Dimension: "error.height", Err: err}
+	}
+
+	if height < minSize || height > maxSize {
+		return HeightRangeError{Height: height, Min: minSize, Max: maxSize}
+	}
+
+	return nil
+}
+
+// validateKeyboard checks if the visible window of the board with its controls
+// fits into inline keyboard, the window is never wider or taller than
+// viewportSize so only serialized size of the keyboard can exceed the limits
+func validateKeyboard(width, height int64) error {
+	if size := estimateKeyboardSize(int(width), int(height)); size > maxKeyboardBytes {
+		slog.Warn("Board rejected by keyboard size estimate", "width", width, "height", height, "size", size)
+		return KeyboardError{Width: width, Height: height}
+	}
+
+	return nil
+}
+
+func validateMines(width, height, mines int64, err error) error {
+	if err != nil {
+		return MinesParseError{Err: err}
+	}
+
+	cells := float64(width * height)
+	lowest := max(minMines, int64(math.Ceil(cells*botConfig.MinDensity)))
+	highest := int64(cells * botConfig.MaxDensity)
+	if mines < lowest || mines > highest {
+		return MinesRangeError{Width: width, Height: height, Mines: mines, Min: lowest, Max: highest}
+	}
+
+	return nil
+}
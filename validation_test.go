@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// answers returns ask func replying with given answers in order
+func answers(values ...string) func(string, []int64) string {
+	return func(string, []int64) string {
+		value := values[0]
+		values = values[1:]
+		return value
+	}
+}
+
+func TestReadMinefieldHeight(t *testing.T) {
+	tests := []struct {
+		answers []string
+		want    error
+	}{
+		{[]string{"5", "50", "0", "50"}, HeightRangeError{Height: 50, Min: minSize, Max: maxSize}},
+		{[]string{"5", "0", "0", "0"}, HeightRangeError{Height: 0, Min: minSize, Max: maxSize}},
+		{[]string{"50", "50", "50"}, WidthRangeError{Width: 50, Min: minSize, Max: maxSize}},
+	}
+
+	for _, test := range tests {
+		retries := 0
+		minefield, err := readMinefield(answers(test.answers...), func(error, int) { retries++ }, defaultLanguage, 1)
+		if err != test.want {
+			t.Errorf("readMinefield(%v) error = %v, want %v", test.answers, err, test.want)
+		}
+
+		if retries != maxPromptAttempts-1 {
+			t.Errorf("readMinefield(%v) retried %d times, want %d", test.answers, retries, maxPromptAttempts-1)
+		}
+
+		if minefield != nil {
+			t.Errorf("readMinefield(%v) created minefield", test.answers)
+		}
+	}
+}
+
+func TestValidateMinesDensity(t *testing.T) {
+	tests := []struct {
+		name       string
+		minDensity float64
+		maxDensity float64
+		wantMin    int64
+		wantMax    int64
+	}{
+		{"default", 0, defaultMaxDensity, minMines, int64(100 * defaultMaxDensity)},
+		{"dense", 0, 0.9, minMines, 90},
+		{"sparse", 0.1, 0.2, 10, 20},
+	}
+
+	for _, test := range tests {
+		withConfig(t, BotConfig{MinDensity: test.minDensity, MaxDensity: test.maxDensity})
+		if err := validateMines(10, 10, test.wantMin-1, nil); err != (MinesRangeError{Width: 10, Height: 10, Mines: test.wantMin - 1, Min: test.wantMin, Max: test.wantMax}) {
+			t.Errorf("%s: validateMines() of %d mines = %v, want lower bound error", test.name, test.wantMin-1, err)
+		}
+
+		if err := validateMines(10, 10, test.wantMax+1, nil); err != (MinesRangeError{Width: 10, Height: 10, Mines: test.wantMax + 1, Min: test.wantMin, Max: test.wantMax}) {
+			t.Errorf("%s: validateMines() of %d mines = %v, want upper bound error", test.name, test.wantMax+1, err)
+		}
+
+		for _, mines := range []int64{test.wantMin, test.wantMax} {
+			if err := validateMines(10, 10, mines, nil); err != nil {
+				t.Errorf("%s: validateMines() of %d mines = %v, want nil", test.name, mines, err)
+			}
+		}
+	}
+}
+
+func TestParseMinefieldErrors(t *testing.T) {
+	withConfig(t, BotConfig{MaxDensity: defaultMaxDensity})
+	tests := []struct {
+		args    string
+		wantErr error
+	}{
+		{"8 8 10", nil},
+		{"0 8 10", WidthRangeError{}},
+		{"99999999999 8 10", SizeParseError{}},
+		{"8 1000 10", HeightRangeError{}},
+		{"8 99999999999 10", SizeParseError{}},
+		{"8 8 0", MinesRangeError{}},
+		{"8 8 64", MinesRangeError{}},
+		{"8 8 99999999999", MinesParseError{}},
+	}
+
+	for _, test := range tests {
+		args := strings.Fields(test.args)
+		minefield, err := parseMinefield(testRequest(1, "/play "+test.args), defaultLanguage, args, 1)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.wantErr) {
+			t.Errorf("parseMinefield(%q) error = %T %v, want %T", test.args, err, err, test.wantErr)
+		}
+
+		if (minefield == nil) != (test.wantErr != nil) {
+			t.Errorf("parseMinefield(%q) minefield = %v", test.args, minefield)
+		}
+
+		if err != nil && len(errorText(defaultLanguage, err)) == 0 {
+			t.Errorf("parseMinefield(%q) error has no text", test.args)
+		}
+	}
+
+	if _, _, err := parseSeed([]string{"8", "seed:abc"}); reflect.TypeOf(err) != reflect.TypeOf(SeedParseError{}) {
+		t.Errorf("parseSeed() error = %T %v, want SeedParseError", err, err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/floodcode/tbf"
+)
+
+// Build info injected at link time, e.g.
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// versionAction reports build info, admins also see mode and count of active games
+func versionAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	text := tr(lang, "version.build", version, commit, buildDate)
+	if isAdmin(req.Message.From.ID) {
+		mode := botConfig.Mode
+		if len(mode) == 0 {
+			mode = modePoll
+		}
+
+		text += "\n" + tr(lang, "version.status", mode, games.Count())
+	}
+
+	req.QuickMessage(text)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"github.com/floodcode/tgbot"
+)
+
+// Boards wider or taller than viewportSize are shown by a window of cells
+// which is scrolled by scrollStep cells with navigation buttons
+const (
+	viewportSize = maxKeyboardWidth
+	scrollStep   = viewportSize / 2
+)
+
+const (
+	scrollUp    = "up"
+	scrollDown  = "down"
+	scrollLeft  = "left"
+	scrollRight = "right"
+)
+
+var scrollGlyphs = map[string]string{
+	scrollUp:    "⬆️",
+	scrollDown:  "⬇️",
+	scrollLeft:  "⬅️",
+	scrollRight: "➡️",
+}
+
+// viewport returns visible window of the board as its top left cell and size
+func viewport(game *Game) (row, col, height, width int) {
+	minefield := game.Minefield
+	height = min(minefield.Height, viewportSize)
+	width = min(minefield.Width, viewportSize)
+	row = max(0, min(game.Viewport.Row, minefield.Height-height))
+	col = max(0, min(game.Viewport.Col, minefield.Width-width))
+	return row, col, height, width
+}
+
+// paginated checks if the board doesn't fit into a single keyboard
+func paginated(minefield *Minefield) bool {
+	return minefield.Width > viewportSize || minefield.Height > viewportSize
+}
+
+// scrollViewport moves visible window of the board in the direction
+// and returns if it was moved
+func scrollViewport(game *Game, direction string) bool {
+	row, col, height, width := viewport(game)
+	switch direction {
+	case scrollUp:
+		row = max(0, row-scrollStep)
+	case scrollDown:
+		row = min(game.Minefield.Height-height, row+scrollStep)
+	case scrollLeft:
+		col = max(0, col-scrollStep)
+	case scrollRight:
+		col = min(game.Minefield.Width-width, col+scrollStep)
+	}
+
+	if row == game.Viewport.Row && col == game.Viewport.Col {
+		return false
+	}
+
+	game.Viewport = Position{Row: row, Col: col}
+	return true
+}
+
+// renderNavigation renders buttons scrolling the window in directions
+// where part of the board is hidden
+func renderNavigation(game *Game) []tgbot.InlineKeyboardButton {
+	row, col, height, width := viewport(game)
+	hidden := map[string]bool{
+		scrollUp:    row > 0,
+		scrollDown:  row+height < game.Minefield.Height,
+		scrollLeft:  col > 0,
+		scrollRight: col+width < game.Minefield.Width,
+	}
+
+	var buttons []tgbot.InlineKeyboardButton
+	for _, direction := range []string{scrollLeft, scrollUp, scrollDown, scrollRight} {
+		if !hidden[direction] {
+			continue
+		}
+
+		buttons = append(buttons, tgbot.InlineKeyboardButton{
+			Text: scrollGlyphs[direction],
+			CallbackData: encodeCallbackData(CellCallbackData{
+				Action: actionScroll,
+				Value:  direction,
+			}),
+		})
+	}
+
+	return buttons
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestScrollKeepsAbsoluteCoordinates(t *testing.T) {
+	game := &Game{Minefield: newMinefield(20, 14, 30, 1)}
+	tests := []struct {
+		direction string
+		moved     bool
+		want      Position
+	}{
+		{scrollUp, false, Position{0, 0}},
+		{scrollRight, true, Position{0, scrollStep}},
+		{scrollDown, true, Position{scrollStep, scrollStep}},
+		{scrollDown, true, Position{14 - viewportSize, scrollStep}},
+		{scrollDown, false, Position{14 - viewportSize, scrollStep}},
+		{scrollRight, true, Position{14 - viewportSize, 2 * scrollStep}},
+		{scrollRight, true, Position{14 - viewportSize, 20 - viewportSize}},
+		{scrollLeft, true, Position{14 - viewportSize, 20 - viewportSize - scrollStep}},
+	}
+
+	for i, test := range tests {
+		if moved := scrollViewport(game, test.direction); moved != test.moved {
+			t.Errorf("step %d: scrollViewport(%s) = %v, want %v", i+1, test.direction, moved, test.moved)
+		}
+
+		top, left, _, _ := viewport(game)
+		if top != test.want.Row || left != test.want.Col {
+			t.Errorf("step %d: viewport at %d,%d, want %v", i+1, top, left, test.want)
+		}
+
+		for row, buttons := range renderCells(game, false) {
+			for col, button := range buttons {
+				data, err := decodeCallbackData(button.CallbackData)
+				if err != nil || data.Row != top+row || data.Col != left+col {
+					t.Fatalf("step %d: button %d,%d taps %+v, want %d,%d", i+1, row, col, data, top+row, left+col)
+				}
+			}
+		}
+	}
+
+	data, _ := decodeCallbackData(renderCells(game, false)[1][2].CallbackData)
+	game.Minefield.Open(data.Row, data.Col)
+	if state := game.Minefield.Field[14-viewportSize+1][20-viewportSize-scrollStep+2].State; state != StateOpened {
+		t.Errorf("tapped cell has state %d, want opened", state)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/floodcode/tbf"
+	"github.com/floodcode/tgbot"
+)
+
+const (
+	watchPrefix = "watch_"
+	maxMirrors  = 10
+)
+
+// Mirror contains read-only copy of game board posted to another chat
+type Mirror struct {
+	ChatID    int `json:"chat_id"`
+	MessageID int `json:"message_id"`
+}
+
+func newWatchID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// watchAction replies with deep link posting read-only mirror of current game
+func watchAction(req tbf.Request) {
+	lang := userLanguage(req.Message.From)
+	game, ok := commandGame(req)
+	if !ok {
+		req.QuickMessage(tr(lang, "game.not_found"))
+		return
+	}
+
+	me, err := req.Bot.GetMe()
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to get bot info", "error", err)
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", me.Username, watchPrefix, game.WatchID)
+	req.QuickMessage(tr(lang, "watch.link", link))
+}
+
+// startWatching posts read-only mirror of the game to the chat,
+// it's called when user opens link sent by /watch
+func startWatching(req tbf.Request, watchID string) {
+	lang := userLanguage(req.Message.From)
+	game, ok := games.GetByWatchID(watchID)
+	if !ok {
+		req.QuickMessage(tr(lang, "game.expired"))
+		return
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if len(game.Mirrors) >= maxMirrors {
+		req.QuickMessage(tr(lang, "watch.limit"))
+		return
+	}
+
+	msg, err := sendMessage(req.Bot, tgbot.SendMessageConfig{
+		ChatID:      tgbot.ChatID(req.Message.Chat.ID),
+		Text:        renderText(game, tr(game.Language, "game.title")),
+		ReplyMarkup: renderMirror(game),
+	})
+
+	if err != nil {
+		apiErrors.Inc()
+		slog.Error("Unable to send mirror", "game", game.Key(), "error", err)
+		return
+	}
+
+	game.Mirrors = append(game.Mirrors, Mirror{
+		ChatID:    req.Message.Chat.ID,
+		MessageID: msg.MessageID,
+	})
+
+	slog.Debug("Mirror created", "game", game.Key(), "chat_id", req.Message.Chat.ID)
+}
+
+// updateMirrors edits read-only mirrors of the game, game lock should be held
+func updateMirrors(bot *tgbot.TelegramBot, game *Game, text string) {
+	for _, mirror := range game.Mirrors {
+		err := editMessage(bot, tgbot.EditMessageTextConfig{
+			ChatID:      tgbot.ChatID(mirror.ChatID),
+			MessageID:   mirror.MessageID,
+			Text:        text,
+			ReplyMarkup: renderMirror(game),
+		})
+
+		if err != nil {
+			slog.Error("Unable to update mirror", "game", game.Key(), "chat_id", mirror.ChatID, "error", err)
+		}
+	}
+}
+
+// watchPayload extracts game watch ID from /start payload
+func watchPayload(text string) (string, bool) {
+	args := commandArgs(text)
+	if len(args) == 0 || !strings.HasPrefix(args[0], watchPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(args[0], watchPrefix), true
+}